@@ -0,0 +1,156 @@
+package cryptopals
+
+import (
+	"fmt"
+)
+
+// fiveBitsMax is a 5bit value of all binary 1s:
+//   - bin: 11111
+//   - dec: 31
+//   - hex: 0x1F
+const fiveBitsMax = 0x1F
+
+var (
+	// StdBase32 is the standard base32 encoding from RFC 4648.
+	StdBase32 = mustNewBase32Encoding("ABCDEFGHIJKLMNOPQRSTUVWXYZ234567", PadRequired)
+	// HexBase32 is the "Extended Hex" base32 encoding from RFC 4648, which
+	// keeps the sort order of the encoded data.
+	HexBase32 = mustNewBase32Encoding("0123456789ABCDEFGHIJKLMNOPQRSTUV", PadRequired)
+)
+
+// base32DataChars is the number of characters before the padding in a
+// quantum, indexed by how many bytes it contains.
+var base32DataChars = [6]int{0, 2, 4, 5, 7, 8}
+
+// Base32Encoding is a base32 alphabet and padding policy.
+type Base32Encoding struct {
+	quantumEncoding
+	// alphabet is used to lookup base32 characters using zero-indexed 5bit
+	// values
+	alphabet string
+}
+
+// NewBase32Encoding returns an encoding for a 32 character alphabet. The
+// alphabet can't contain duplicates, the padding character '=' or
+// whitespace.
+func NewBase32Encoding(alphabet string, padding PadPolicy) (*Base32Encoding, error) {
+	if len(alphabet) != 32 {
+		return nil, fmt.Errorf("alphabet must be 32 characters: %d", len(alphabet))
+	}
+
+	enc := &Base32Encoding{
+		quantumEncoding: quantumEncoding{
+			name:          "base32",
+			padding:       padding,
+			dataChars:     base32DataChars[:],
+			appendQuantum: appendBase32Quantum,
+		},
+		alphabet: alphabet,
+	}
+	if err := enc.setAlphabet(alphabet); err != nil {
+		return nil, err
+	}
+
+	return enc, nil
+}
+
+// mustNewBase32Encoding is used to declare the built-in encodings, which
+// are known to be valid.
+func mustNewBase32Encoding(alphabet string, padding PadPolicy) *Base32Encoding {
+	enc, err := NewBase32Encoding(alphabet, padding)
+	if err != nil {
+		panic(err)
+	}
+
+	return enc
+}
+
+// Base32Encode encodes a byte slice into a standard base32 byte slice.
+func Base32Encode(text []byte) []byte {
+	return StdBase32.Encode(text)
+}
+
+// Base32Decode decodes a standard base32 byte slice into a byte slice.
+func Base32Decode(text []byte) ([]byte, error) {
+	return StdBase32.Decode(text)
+}
+
+// Encode encodes a byte slice into a base32 byte slice.
+func (e *Base32Encoding) Encode(text []byte) []byte {
+	// 8 characters out for every 5 bytes in
+	out := make([]byte, 0, (len(text)+4)/5*8)
+
+	for i := 0; i < len(text); i += 5 {
+		// how many bytes of input are left for this block, which is less
+		// than 5 if we've reached the end of the input
+		remaining := len(text) - i
+		if remaining > 5 {
+			remaining = 5
+		}
+
+		// convert five 8bit characters into one 40bit value by:
+		// - converting from byte to uint64
+		// - bit shifting to the left, to pad least significant bits, so that they don't overlap
+		// - bit ORing to combine the values into one
+		// - treating missing characters as zero if we've reached the end of the input
+		var combined uint64
+		for j := 0; j < 5; j++ {
+			combined <<= 8
+			if j < remaining {
+				combined |= uint64(text[i+j])
+			}
+		}
+
+		// split the 40bit value into eight 5bit values by:
+		// - bit shifting to the right, so the least significant 5bits are what we want
+		// - bit ANDing against 5bits of binary 1s to extract the least significant 5bits
+		// - looking up the appropriate base32 character for the value
+		// - only keeping the characters that contain some bits of input
+		var chars [8]byte
+		for j := range chars {
+			chars[j] = e.alphabet[combined>>uint(35-j*5)&fiveBitsMax]
+		}
+		dataChars := base32DataChars[remaining]
+		out = append(out, chars[:dataChars]...)
+
+		// pad the rest of the characters if we've reached the end of the input
+		if e.padding != PadNone {
+			for j := dataChars; j < len(chars); j++ {
+				out = append(out, '=')
+			}
+		}
+	}
+
+	return out
+}
+
+// Decode decodes a base32 byte slice into a byte slice. Whitespace is
+// ignored. Malformed input is returned as a *DecodeError with the offset of
+// the problem.
+func (e *Base32Encoding) Decode(text []byte) ([]byte, error) {
+	return e.decode(text)
+}
+
+// appendBase32Quantum decodes eight 5bit values into five bytes and appends
+// the first size of them to out.
+func appendBase32Quantum(out, quantum []byte, size int) []byte {
+	// convert eight 5bit characters into one 40bit value by:
+	// - converting from byte to uint64
+	// - bit shifting to the left, to pad least significant bits, so that they don't overlap
+	// - bit ORing to combine the values into one
+	var combined uint64
+	for _, v := range quantum {
+		combined = combined<<5 | uint64(v)
+	}
+
+	// split the 40bit value into five 8bit values by:
+	// - bit shifting to the right, so the least significant 8bits are what we want
+	// - bit ANDing against 8bits of binary 1s to extract the least significant 8bits
+	// - only keeping as many bytes as weren't replaced by padding
+	var decoded [5]byte
+	for i := range decoded {
+		decoded[i] = byte(combined >> uint(32-i*8) & eightBitsMax)
+	}
+
+	return append(out, decoded[:size]...)
+}
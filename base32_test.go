@@ -0,0 +1,122 @@
+package cryptopals_test
+
+import (
+	"encoding/base32"
+
+	. "github.com/dcarley/cryptopals"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Base32", func() {
+	DescribeTable("round trips",
+		func(input string) {
+			for _, encs := range []struct {
+				enc *Base32Encoding
+				std *base32.Encoding
+			}{
+				{StdBase32, base32.StdEncoding},
+				{HexBase32, base32.HexEncoding},
+			} {
+				encoded := encs.enc.Encode([]byte(input))
+				Expect(string(encoded)).To(Equal(encs.std.EncodeToString([]byte(input))))
+
+				decoded, err := encs.enc.Decode(encoded)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(decoded).To(Equal([]byte(input)))
+			}
+		},
+		Entry("empty", ""),
+		Entry("1 byte, 6 padding characters", "f"),
+		Entry("2 bytes, 4 padding characters", "fo"),
+		Entry("3 bytes, 3 padding characters", "foo"),
+		Entry("4 bytes, 1 padding character", "foob"),
+		Entry("5 bytes, no padding", "fooba"),
+		Entry("6 bytes, 6 padding characters", "foobar"),
+		Entry("binary", "\x00\xff\x80\x7f\x01\xfe\x00"),
+	)
+
+	It("should encode and decode with Base32Encode and Base32Decode", func() {
+		encoded := Base32Encode([]byte("hello gopher"))
+		Expect(encoded).To(Equal([]byte("NBSWY3DPEBTW64DIMVZA====")))
+
+		decoded, err := Base32Decode(encoded)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(decoded).To(Equal([]byte("hello gopher")))
+	})
+
+	It("should ignore whitespace", func() {
+		decoded, err := Base32Decode([]byte("NBSWY3DP\r\nEBTW64DI\r\nMVZA====\r\n"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(decoded).To(Equal([]byte("hello gopher")))
+	})
+
+	It("should encode and decode without padding", func() {
+		enc, err := NewBase32Encoding("ABCDEFGHIJKLMNOPQRSTUVWXYZ234567", PadNone)
+		Expect(err).ToNot(HaveOccurred())
+
+		encoded := enc.Encode([]byte("hello gopher"))
+		Expect(encoded).To(Equal([]byte("NBSWY3DPEBTW64DIMVZA")))
+
+		decoded, err := enc.Decode(encoded)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(decoded).To(Equal([]byte("hello gopher")))
+	})
+
+	DescribeTable("optional padding",
+		func(encoded, expected string) {
+			enc, err := NewBase32Encoding("ABCDEFGHIJKLMNOPQRSTUVWXYZ234567", PadOptional)
+			Expect(err).ToNot(HaveOccurred())
+
+			decoded, err := enc.Decode([]byte(encoded))
+			if err != nil {
+				Expect("error: " + err.Error()).To(Equal(expected))
+				Expect(decoded).To(Equal([]byte{}))
+				return
+			}
+			Expect(string(decoded)).To(Equal(expected))
+		},
+		Entry("with padding", "MY======", "f"),
+		Entry("without padding", "MY", "f"),
+		Entry("incomplete padding", "MY=====", "error: input is truncated at offset 7"),
+		Entry("incomplete padding after a quantum", "MZXW6YTBMY===", "error: input is truncated at offset 13"),
+	)
+
+	DescribeTable("errors",
+		func(encoded, expected string) {
+			decoded, err := Base32Decode([]byte(encoded))
+			Expect(err).To(MatchError(expected))
+			Expect(decoded).To(Equal([]byte{}))
+		},
+		Entry("invalid character",
+			"NBSWY1DP", "invalid base32 character: 1 at offset 5",
+		),
+		Entry("lowercase character",
+			"nbswy3dp", "invalid base32 character: n at offset 0",
+		),
+		Entry("truncated input",
+			"NBSWY3DPEB", "input is truncated at offset 10",
+		),
+		Entry("padding after a partial byte",
+			"NBS=====", "unexpected padding at offset 3",
+		),
+		Entry("data after padding",
+			"MY======MY======", "unexpected data after padding at offset 8",
+		),
+	)
+
+	DescribeTable("invalid alphabets",
+		func(alphabet, expected string) {
+			enc, err := NewBase32Encoding(alphabet, PadRequired)
+			Expect(err).To(MatchError(expected))
+			Expect(enc).To(BeNil())
+		},
+		Entry("too short", "ABC", "alphabet must be 32 characters: 3"),
+		Entry("duplicate characters", "AABCDEFGHIJKLMNOPQRSTUVWXYZ23456", "alphabet contains duplicate character: 'A'"),
+		Entry("padding character", "=BCDEFGHIJKLMNOPQRSTUVWXYZ234567", "alphabet contains reserved character: '='"),
+		Entry("space character", " BCDEFGHIJKLMNOPQRSTUVWXYZ234567", "alphabet contains reserved character: ' '"),
+		Entry("tab character", "\tBCDEFGHIJKLMNOPQRSTUVWXYZ234567", "alphabet contains reserved character: '\\t'"),
+	)
+})
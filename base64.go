@@ -0,0 +1,189 @@
+package cryptopals
+
+import (
+	"fmt"
+)
+
+// PadPolicy controls whether an encoding uses padding characters to make the
+// output a whole number of blocks.
+type PadPolicy int
+
+const (
+	// PadRequired pads encoded output and requires padding when decoding.
+	PadRequired PadPolicy = iota
+	// PadOptional pads encoded output but accepts decoding input with or
+	// without padding.
+	PadOptional
+	// PadNone doesn't pad encoded output and rejects padding when decoding.
+	PadNone
+)
+
+// base64URLAlphabet is the "URL and Filename safe" alphabet from RFC 4648,
+// which replaces the characters that have special meanings in URLs.
+const base64URLAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+
+// invalidChar marks characters in a decode map that aren't in the alphabet.
+const invalidChar = 0xFF
+
+var (
+	// StdBase64 is the standard base64 encoding from RFC 4648.
+	StdBase64 = mustNewBase64Encoding(decToBase64, PadRequired)
+	// URLBase64 is the URL and filename safe base64 encoding from RFC 4648.
+	URLBase64 = mustNewBase64Encoding(base64URLAlphabet, PadRequired)
+	// RawStdBase64 is the standard base64 encoding without padding.
+	RawStdBase64 = mustNewBase64Encoding(decToBase64, PadNone)
+	// RawURLBase64 is the URL and filename safe base64 encoding without
+	// padding.
+	RawURLBase64 = mustNewBase64Encoding(base64URLAlphabet, PadNone)
+)
+
+// Base64Encoding is a base64 alphabet and padding policy.
+type Base64Encoding struct {
+	quantumEncoding
+	// alphabet is used to lookup base64 characters using zero-indexed 6bit
+	// values
+	alphabet string
+}
+
+// base64DataChars is the number of characters before the padding in a
+// quantum, indexed by how many bytes it contains.
+var base64DataChars = [4]int{0, 2, 3, 4}
+
+// NewBase64Encoding returns an encoding for a 64 character alphabet, such as
+// a shuffled one. The alphabet can't contain duplicates, the padding
+// character '=' or whitespace.
+func NewBase64Encoding(alphabet string, padding PadPolicy) (*Base64Encoding, error) {
+	if len(alphabet) != 64 {
+		return nil, fmt.Errorf("alphabet must be 64 characters: %d", len(alphabet))
+	}
+
+	enc := &Base64Encoding{
+		quantumEncoding: quantumEncoding{
+			name:          "base64",
+			padding:       padding,
+			dataChars:     base64DataChars[:],
+			appendQuantum: appendBase64Quantum,
+		},
+		alphabet: alphabet,
+	}
+	if err := enc.setAlphabet(alphabet); err != nil {
+		return nil, err
+	}
+
+	return enc, nil
+}
+
+// mustNewBase64Encoding is used to declare the built-in encodings, which
+// are known to be valid.
+func mustNewBase64Encoding(alphabet string, padding PadPolicy) *Base64Encoding {
+	enc, err := NewBase64Encoding(alphabet, padding)
+	if err != nil {
+		panic(err)
+	}
+
+	return enc
+}
+
+// EncodedLen returns the size of the output from encoding n bytes.
+func (e *Base64Encoding) EncodedLen(n int) int {
+	if e.padding == PadNone {
+		// 4 characters for every 3 bytes, rounding up to the nearest
+		// character that contains some bits of input
+		return (n*8 + 5) / 6
+	}
+
+	// 4 characters for every 3 bytes, padded to a multiple of 4
+	return (n + 2) / 3 * 4
+}
+
+// Encode encodes a byte slice into a base64 byte slice.
+func (e *Base64Encoding) Encode(text []byte) []byte {
+	out := make([]byte, 0, e.EncodedLen(len(text)))
+
+	for i := 0; i < len(text); i += 3 {
+		// how many bytes of input are left for this block, which is less
+		// than 3 if we've reached the end of the input
+		remaining := len(text) - i
+		if remaining > 3 {
+			remaining = 3
+		}
+
+		// convert three 8bit characters into one 24bit value by:
+		// - converting from byte (int8) to int32
+		// - bit shifting to the left, to pad least significant bits, so that they don't overlap
+		// - bit ORing to combine the values into one
+		// - ignore the last two characters if we've reached the end of the input
+		combined := int32(text[i]) << 16
+		if remaining > 1 {
+			combined |= int32(text[i+1]) << 8
+		}
+		if remaining > 2 {
+			combined |= int32(text[i+2]) << 0
+		}
+
+		// split the 24bit value into four 6bit values by:
+		// - bit shifting to the right, so the least significant 6bits are what we want
+		// - bit ANDing against 6bits of binary 1s to extract the least significant 6bits
+		// - looking up the appropriate base64 character for the value
+		// - only keeping the characters that contain some bits of input
+		chars := []byte{
+			e.alphabet[combined>>18&sixBitsMax],
+			e.alphabet[combined>>12&sixBitsMax],
+			e.alphabet[combined>>6&sixBitsMax],
+			e.alphabet[combined>>0&sixBitsMax],
+		}
+		out = append(out, chars[:remaining+1]...)
+
+		// pad the last two characters if we've reached the end of the input
+		if e.padding != PadNone {
+			for j := remaining; j < 3; j++ {
+				out = append(out, '=')
+			}
+		}
+	}
+
+	return out
+}
+
+// Decode decodes a base64 byte slice into an byte slice. Whitespace is
+// ignored. Malformed input is returned as a *DecodeError with the offset of
+// the problem.
+func (e *Base64Encoding) Decode(text []byte) ([]byte, error) {
+	return e.decode(text)
+}
+
+// isWhitespace reports whether a character should be ignored when decoding.
+func isWhitespace(char byte) bool {
+	switch char {
+	case ' ', '\t', '\n', '\v', '\f', '\r':
+		return true
+	}
+
+	return false
+}
+
+// appendBase64Quantum decodes four 6bit values into three bytes and appends
+// the first size of them to out.
+func appendBase64Quantum(out, quantum []byte, size int) []byte {
+	// convert four 6bit characters into one 24bit value by:
+	// - converting from byte to int32
+	// - bit shifting to the left, to pad least significant bits, so that they don't overlap
+	// - bit ORing to combine the values into one
+	combined := int32(quantum[0])<<18 |
+		int32(quantum[1])<<12 |
+		int32(quantum[2])<<6 |
+		int32(quantum[3])<<0
+
+	// split the 24bit value into three 8bit values by:
+	// - bit shifting to the right, so the least significant 8bits are what we want
+	// - bit ANDing against 8bits of binary 1s to extract the least significant 8bits
+	// - converting to byte so that we get the appropriate ASCII code
+	// - only keeping as many bytes as weren't replaced by padding
+	decoded := []byte{
+		byte(combined >> 16 & eightBitsMax),
+		byte(combined >> 8 & eightBitsMax),
+		byte(combined >> 0 & eightBitsMax),
+	}
+
+	return append(out, decoded[:size]...)
+}
@@ -0,0 +1,198 @@
+package cryptopals
+
+import (
+	"io"
+)
+
+const (
+	// PEMLineLength is the number of characters per line in PEM files.
+	PEMLineLength = 64
+	// MIMELineLength is the maximum number of characters per line in MIME
+	// email bodies.
+	MIMELineLength = 76
+)
+
+// base64BufferSize is how many characters the streaming base64 encoder and
+// decoder will hold in memory at once.
+const base64BufferSize = 1024
+
+// base64Encoder base64 encodes everything written to it before passing it on
+// to an underlying writer.
+type base64Encoder struct {
+	enc        *Base64Encoding
+	w          io.Writer
+	lineLength int
+	column     int     // number of characters written to the current line
+	pending    [3]byte // bytes that don't make a whole block yet
+	numPending int
+	err        error
+}
+
+// NewEncoder returns a writer that base64 encodes everything written to it
+// and writes the result to w. If lineLength is greater than zero then the
+// output is wrapped with a newline after that many characters, such as
+// PEMLineLength. Close must be called to write any partial block and the
+// final newline.
+func (e *Base64Encoding) NewEncoder(w io.Writer, lineLength int) io.WriteCloser {
+	return &base64Encoder{
+		enc:        e,
+		w:          w,
+		lineLength: lineLength,
+	}
+}
+
+func (e *base64Encoder) Write(p []byte) (int, error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+
+	var n int
+	for len(p) > 0 {
+		// fill up the pending bytes before encoding anything else, so that
+		// padding is never added in the middle of the output
+		if e.numPending > 0 || len(p) < len(e.pending) {
+			copied := copy(e.pending[e.numPending:], p)
+			e.numPending += copied
+			n += copied
+			p = p[copied:]
+
+			if e.numPending < len(e.pending) {
+				break
+			}
+
+			if e.err = e.writeWrapped(e.enc.Encode(e.pending[:])); e.err != nil {
+				return n, e.err
+			}
+			e.numPending = 0
+			continue
+		}
+
+		// encode whole blocks of 3 bytes, leaving any remainder for later
+		chunk := len(p) / 3 * 3
+		if chunk > base64BufferSize/4*3 {
+			chunk = base64BufferSize / 4 * 3
+		}
+
+		if e.err = e.writeWrapped(e.enc.Encode(p[:chunk])); e.err != nil {
+			return n, e.err
+		}
+		n += chunk
+		p = p[chunk:]
+	}
+
+	return n, nil
+}
+
+// Close writes any partial block, with padding if the encoding uses it, and
+// ends the last line with a newline if the output is being wrapped. It
+// doesn't close the underlying writer.
+func (e *base64Encoder) Close() error {
+	if e.err != nil {
+		return e.err
+	}
+
+	if e.numPending > 0 {
+		if e.err = e.writeWrapped(e.enc.Encode(e.pending[:e.numPending])); e.err != nil {
+			return e.err
+		}
+		e.numPending = 0
+	}
+
+	if e.lineLength > 0 && e.column > 0 {
+		if _, e.err = e.w.Write([]byte{'\n'}); e.err != nil {
+			return e.err
+		}
+		e.column = 0
+	}
+
+	return nil
+}
+
+// writeWrapped writes characters to the underlying writer, inserting a
+// newline whenever a line is full. The newline is only written once there
+// are more characters for the next line, so that Close can tell whether the
+// last line needs one.
+func (e *base64Encoder) writeWrapped(chars []byte) error {
+	if e.lineLength <= 0 {
+		_, err := e.w.Write(chars)
+		return err
+	}
+
+	for len(chars) > 0 {
+		if e.column == e.lineLength {
+			if _, err := e.w.Write([]byte{'\n'}); err != nil {
+				return err
+			}
+			e.column = 0
+		}
+
+		size := e.lineLength - e.column
+		if size > len(chars) {
+			size = len(chars)
+		}
+
+		if _, err := e.w.Write(chars[:size]); err != nil {
+			return err
+		}
+		e.column += size
+		chars = chars[size:]
+	}
+
+	return nil
+}
+
+// base64Decoder decodes base64 read from an underlying reader.
+type base64Decoder struct {
+	r      io.Reader
+	err    error
+	state  quantumState
+	offset int64  // number of characters read from r
+	out    []byte // bytes decoded but not yet returned
+	in     [base64BufferSize]byte
+	outBuf [base64BufferSize/4*3 + 3]byte
+}
+
+// NewDecoder returns a reader that decodes base64 read from r. Any
+// whitespace, such as CRLF line endings, is ignored. Malformed input is
+// returned as a *DecodeError with its offset from the start of the input.
+func (e *Base64Encoding) NewDecoder(r io.Reader) io.Reader {
+	return &base64Decoder{
+		r:     r,
+		state: quantumState{enc: &e.quantumEncoding},
+	}
+}
+
+func (d *base64Decoder) Read(p []byte) (int, error) {
+	// keep reading until we have something to return, because a read of
+	// whitespace or part of a quantum won't decode to anything
+	for len(d.out) == 0 {
+		if d.err != nil {
+			return 0, d.err
+		}
+
+		n, err := d.r.Read(d.in[:])
+		out, decodeErr := d.state.decode(d.outBuf[:0], d.in[:n], d.offset)
+		d.offset += int64(n)
+
+		switch {
+		case decodeErr != nil:
+			d.err = decodeErr
+		case err == io.EOF:
+			out, decodeErr = d.state.end(out, d.offset)
+			if decodeErr != nil {
+				d.err = decodeErr
+			} else {
+				d.err = io.EOF
+			}
+		case err != nil:
+			d.err = err
+		}
+
+		d.out = out
+	}
+
+	n := copy(p, d.out)
+	d.out = d.out[n:]
+
+	return n, nil
+}
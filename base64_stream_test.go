@@ -0,0 +1,167 @@
+package cryptopals_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing/iotest"
+
+	. "github.com/dcarley/cryptopals"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Base64 streams", func() {
+	// wrap splits encoded text into lines of size, the same as openssl
+	wrap := func(encoded string, size int) string {
+		var out strings.Builder
+		for len(encoded) > size {
+			out.WriteString(encoded[:size] + "\n")
+			encoded = encoded[size:]
+		}
+		if len(encoded) > 0 {
+			out.WriteString(encoded + "\n")
+		}
+
+		return out.String()
+	}
+
+	input := bytes.Repeat([]byte("hello gophers!"), 100)
+
+	Describe("NewEncoder", func() {
+		DescribeTable("line wrapping",
+			func(lineLength int) {
+				var out bytes.Buffer
+				encoder := StdBase64.NewEncoder(&out, lineLength)
+				n, err := encoder.Write(input)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(n).To(Equal(len(input)))
+				Expect(encoder.Close()).To(Succeed())
+
+				Expect(out.String()).To(Equal(
+					wrap(base64.StdEncoding.EncodeToString(input), lineLength),
+				))
+			},
+			Entry("PEM line length", PEMLineLength),
+			Entry("MIME line length", MIMELineLength),
+			Entry("line length that isn't a multiple of 4", 10),
+		)
+
+		It("should not wrap when line length is zero", func() {
+			var out bytes.Buffer
+			encoder := StdBase64.NewEncoder(&out, 0)
+			_, err := encoder.Write(input)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(encoder.Close()).To(Succeed())
+
+			Expect(out.String()).To(Equal(base64.StdEncoding.EncodeToString(input)))
+		})
+
+		It("should only pad at the end when written in small pieces", func() {
+			var out bytes.Buffer
+			encoder := StdBase64.NewEncoder(&out, 0)
+			for _, char := range []byte("hello gophers") {
+				_, err := encoder.Write([]byte{char})
+				Expect(err).ToNot(HaveOccurred())
+			}
+			Expect(encoder.Close()).To(Succeed())
+
+			Expect(out.String()).To(Equal("aGVsbG8gZ29waGVycw=="))
+		})
+
+		It("should not pad with an unpadded encoding", func() {
+			var out bytes.Buffer
+			encoder := RawURLBase64.NewEncoder(&out, 0)
+			_, err := encoder.Write([]byte("hello gophers"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(encoder.Close()).To(Succeed())
+
+			Expect(out.String()).To(Equal("aGVsbG8gZ29waGVycw"))
+		})
+	})
+
+	Describe("NewDecoder", func() {
+		DescribeTable("whitespace",
+			func(separator string) {
+				encoded := strings.Replace(
+					wrap(base64.StdEncoding.EncodeToString(input), 60), "\n", separator, -1,
+				)
+
+				decoded, err := ioutil.ReadAll(StdBase64.NewDecoder(strings.NewReader(encoded)))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(decoded).To(Equal(input))
+			},
+			Entry("LF", "\n"),
+			Entry("CRLF", "\r\n"),
+			Entry("spaces", "  "),
+			Entry("tabs", "\t"),
+		)
+
+		It("should decode quantums split across reads", func() {
+			decoded, err := ioutil.ReadAll(StdBase64.NewDecoder(
+				iotest.OneByteReader(strings.NewReader("aGVsbG8g\r\nZ29waGVycw==\r\n")),
+			))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(decoded).To(Equal([]byte("hello gophers")))
+		})
+
+		It("should decode unpadded input", func() {
+			decoded, err := ioutil.ReadAll(RawURLBase64.NewDecoder(
+				iotest.HalfReader(strings.NewReader("aGVsbG8gZ29waGVycw")),
+			))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(decoded).To(Equal([]byte("hello gophers")))
+		})
+
+		It("should decode the same as Base64Decode", func() {
+			file, err := os.Open("fixtures/s1c6")
+			Expect(err).ToNot(HaveOccurred())
+			defer file.Close()
+
+			streamed, err := ioutil.ReadAll(StdBase64.NewDecoder(file))
+			Expect(err).ToNot(HaveOccurred())
+
+			b64, err := ioutil.ReadFile("fixtures/s1c6")
+			Expect(err).ToNot(HaveOccurred())
+			decoded, err := Base64Decode(b64)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(streamed).To(Equal(decoded))
+		})
+
+		DescribeTable("errors",
+			func(encoded, expected string, partial []byte) {
+				decoded, err := ioutil.ReadAll(StdBase64.NewDecoder(
+					iotest.OneByteReader(strings.NewReader(encoded)),
+				))
+				Expect(err).To(MatchError(expected))
+				Expect(decoded).To(Equal(partial))
+			},
+			Entry("invalid character",
+				"aGVs\r\nbG!g", "invalid base64 character: ! at offset 8", []byte("hel"),
+			),
+			Entry("truncated input",
+				"aGVs\r\nbG8", "input is truncated at offset 9", []byte("hel"),
+			),
+			Entry("data after padding",
+				"aGU=\r\naGU=", "unexpected data after padding at offset 6", []byte("he"),
+			),
+		)
+
+		It("should round trip with the encoder", func() {
+			var encoded bytes.Buffer
+			encoder := URLBase64.NewEncoder(&encoded, PEMLineLength)
+			_, err := encoder.Write(input)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(encoder.Close()).To(Succeed())
+
+			decoded, err := ioutil.ReadAll(URLBase64.NewDecoder(&encoded))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(decoded).To(Equal(input))
+		})
+	})
+})
@@ -0,0 +1,134 @@
+package cryptopals_test
+
+import (
+	"encoding/base64"
+	"strings"
+
+	. "github.com/dcarley/cryptopals"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Base64", func() {
+	// inputs of every length modulo 3, so that each amount of padding is used
+	inputs := []string{
+		"",
+		"h",
+		"he",
+		"hel",
+		"hello gopher",
+		"hello gophers",
+		"hello gophers!",
+		"\xfb\xff\xbf\x00\x00",
+	}
+
+	DescribeTable("round trips",
+		func(enc *Base64Encoding, std *base64.Encoding) {
+			for _, input := range inputs {
+				encoded := enc.Encode([]byte(input))
+				Expect(string(encoded)).To(Equal(std.EncodeToString([]byte(input))))
+				Expect(enc.EncodedLen(len(input))).To(Equal(len(encoded)))
+
+				decoded, err := enc.Decode(encoded)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(decoded).To(Equal([]byte(input)))
+			}
+		},
+		Entry("standard", StdBase64, base64.StdEncoding),
+		Entry("URL safe", URLBase64, base64.URLEncoding),
+		Entry("standard unpadded", RawStdBase64, base64.RawStdEncoding),
+		Entry("URL safe unpadded", RawURLBase64, base64.RawURLEncoding),
+	)
+
+	Describe("NewBase64Encoding", func() {
+		// alphabet reversed, as a CTF target might use
+		const shuffled = "/+9876543210zyxwvutsrqponmlkjihgfedcbaZYXWVUTSRQPONMLKJIHGFEDCBA"
+
+		It("should encode and decode with a custom alphabet", func() {
+			enc, err := NewBase64Encoding(shuffled, PadRequired)
+			Expect(err).ToNot(HaveOccurred())
+
+			std := base64.NewEncoding(shuffled)
+			for _, input := range inputs {
+				encoded := enc.Encode([]byte(input))
+				Expect(string(encoded)).To(Equal(std.EncodeToString([]byte(input))))
+
+				decoded, err := enc.Decode(encoded)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(decoded).To(Equal([]byte(input)))
+			}
+		})
+
+		It("should reject characters that aren't in a custom alphabet", func() {
+			enc, err := NewBase64Encoding(shuffled, PadRequired)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = enc.Decode([]byte("ab-d"))
+			Expect(err).To(MatchError("invalid base64 character: - at offset 2"))
+		})
+
+		DescribeTable("invalid alphabets",
+			func(alphabet, expected string) {
+				enc, err := NewBase64Encoding(alphabet, PadRequired)
+				Expect(err).To(MatchError(expected))
+				Expect(enc).To(BeNil())
+			},
+			Entry("too short",
+				"ABC", "alphabet must be 64 characters: 3",
+			),
+			Entry("duplicate characters",
+				strings.Repeat("AB", 32), "alphabet contains duplicate character: 'A'",
+			),
+			Entry("padding character",
+				"="+shuffled[1:], "alphabet contains reserved character: '='",
+			),
+			Entry("newline character",
+				"\n"+shuffled[1:], "alphabet contains reserved character: '\\n'",
+			),
+			Entry("space character",
+				" "+shuffled[1:], "alphabet contains reserved character: ' '",
+			),
+			Entry("tab character",
+				"\t"+shuffled[1:], "alphabet contains reserved character: '\\t'",
+			),
+		)
+	})
+
+	Describe("padding policies", func() {
+		const urlAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+
+		DescribeTable("decoding",
+			func(padding PadPolicy, encoded, expected string) {
+				enc, err := NewBase64Encoding(urlAlphabet, padding)
+				Expect(err).ToNot(HaveOccurred())
+
+				decoded, err := enc.Decode([]byte(encoded))
+				if strings.HasPrefix(expected, "error: ") {
+					Expect(err).To(MatchError(strings.TrimPrefix(expected, "error: ")))
+					return
+				}
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(string(decoded)).To(Equal(expected))
+			},
+			Entry("required with padding", PadRequired, "aGU=", "he"),
+			Entry("required without padding", PadRequired, "aGU", "error: input is truncated at offset 3"),
+			Entry("optional with padding", PadOptional, "aGU=", "he"),
+			Entry("optional without padding", PadOptional, "aGU", "he"),
+			Entry("optional with one character", PadOptional, "aGVsb", "error: input is truncated at offset 5"),
+			Entry("optional with incomplete padding", PadOptional, "aG=", "error: input is truncated at offset 3"),
+			Entry("optional with incomplete padding after a quantum", PadOptional, "aGVsbA=", "error: input is truncated at offset 7"),
+			Entry("required with incomplete padding", PadRequired, "aG=", "error: input is truncated at offset 3"),
+			Entry("none with padding", PadNone, "aGU=", "error: unexpected padding at offset 3"),
+			Entry("none without padding", PadNone, "aGU", "he"),
+		)
+
+		It("should pad encoded output when padding is optional", func() {
+			enc, err := NewBase64Encoding(urlAlphabet, PadOptional)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(enc.Encode([]byte("he"))).To(Equal([]byte("aGU=")))
+		})
+	})
+})
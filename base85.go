@@ -0,0 +1,253 @@
+package cryptopals
+
+import (
+	"fmt"
+)
+
+// Ascii85 and Z85 both encode 4 bytes as 5 characters, by treating the bytes
+// as a 32bit number and writing it in base 85. They only differ in which
+// characters they use for each digit.
+
+// ascii85Offset is added to a base 85 digit to get an Ascii85 character,
+// which makes the characters '!' through 'u'.
+const ascii85Offset = '!'
+
+// z85Alphabet is used to lookup Z85 characters using zero-indexed base 85
+// digits: https://rfc.zeromq.org/spec/32/
+const z85Alphabet = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ.-:+=^!/*?&<>()[]{}@%$#"
+
+// z85DecodeMap is used to lookup zero-indexed base 85 digits using Z85
+// characters, the reverse of z85Alphabet.
+var z85DecodeMap = func() [256]byte {
+	var decodeMap [256]byte
+	for i := range decodeMap {
+		decodeMap[i] = invalidChar
+	}
+	for i := 0; i < len(z85Alphabet); i++ {
+		decodeMap[z85Alphabet[i]] = byte(i)
+	}
+
+	return decodeMap
+}()
+
+// base85Digits converts four bytes into five base 85 digits, most
+// significant first. Missing bytes at the end of the input are zero.
+func base85Digits(group []byte) [5]byte {
+	// convert four 8bit characters into one 32bit value by:
+	// - bit shifting to the left, to pad least significant bits, so that they don't overlap
+	// - bit ORing to combine the values into one
+	var combined uint32
+	for i := 0; i < 4; i++ {
+		combined <<= 8
+		if i < len(group) {
+			combined |= uint32(group[i])
+		}
+	}
+
+	// repeatedly divide by 85, where the remainder is the next least
+	// significant digit
+	var digits [5]byte
+	for i := len(digits) - 1; i >= 0; i-- {
+		digits[i] = byte(combined % 85)
+		combined /= 85
+	}
+
+	return digits
+}
+
+// appendBase85Group converts five base 85 digits back into four bytes and
+// appends the first size of them to out. It returns false if the digits
+// are too big to fit in 32bits.
+func appendBase85Group(out []byte, digits [5]byte, size int) ([]byte, bool) {
+	var combined uint64
+	for _, digit := range digits {
+		combined = combined*85 + uint64(digit)
+	}
+	if combined > 0xFFFFFFFF {
+		return out, false
+	}
+
+	decoded := []byte{
+		byte(combined >> 24 & eightBitsMax),
+		byte(combined >> 16 & eightBitsMax),
+		byte(combined >> 8 & eightBitsMax),
+		byte(combined >> 0 & eightBitsMax),
+	}
+
+	return append(out, decoded[:size]...), true
+}
+
+// Ascii85Encode encodes a byte slice into an Ascii85 byte slice, as used by
+// btoa and PostScript, without the "<~" and "~>" delimiters.
+func Ascii85Encode(text []byte) []byte {
+	// 5 characters out for every 4 bytes in
+	out := make([]byte, 0, (len(text)+3)/4*5)
+
+	for i := 0; i < len(text); i += 4 {
+		// how many bytes of input are left for this group, which is less
+		// than 4 if we've reached the end of the input
+		remaining := len(text) - i
+		if remaining > 4 {
+			remaining = 4
+		}
+		group := text[i : i+remaining]
+
+		// a whole group of zeros is abbreviated to a single character
+		if remaining == 4 && group[0] == 0 && group[1] == 0 && group[2] == 0 && group[3] == 0 {
+			out = append(out, 'z')
+			continue
+		}
+
+		// a partial group only needs one more character than bytes,
+		// because the missing bytes are zeros that can be inferred
+		digits := base85Digits(group)
+		for _, digit := range digits[:remaining+1] {
+			out = append(out, digit+ascii85Offset)
+		}
+	}
+
+	return out
+}
+
+// Ascii85Decode decodes an Ascii85 byte slice into a byte slice. Whitespace
+// and the optional "<~" and "~>" delimiters are ignored. Malformed input is
+// returned as a *DecodeError with the offset of the problem.
+func Ascii85Decode(text []byte) ([]byte, error) {
+	// 4 bytes out for every 5 bytes in, which may be too small if there are
+	// abbreviated groups of zeros
+	out := make([]byte, 0, len(text)/5*4)
+
+	var (
+		digits [5]byte // digits of the group being decoded
+		count  int     // number of digits in the group
+		start  int     // offset to start decoding from
+		ended  bool    // whether we've seen the "~>" delimiter
+	)
+	if len(text) >= 2 && text[0] == '<' && text[1] == '~' {
+		start = 2
+	}
+
+	for offset := start; offset < len(text); offset++ {
+		char := text[offset]
+		switch {
+		case isWhitespace(char):
+			continue
+		case ended:
+			return []byte{}, &DecodeError{Offset: int64(offset), Reason: "unexpected data after end delimiter"}
+		case char == '~' && offset+1 < len(text) && text[offset+1] == '>':
+			ended = true
+			offset++
+			continue
+		case char == 'z':
+			// abbreviated zeros can't be in the middle of a group
+			if count != 0 {
+				return []byte{}, &DecodeError{Offset: int64(offset), Reason: "unexpected z in group"}
+			}
+
+			out = append(out, 0, 0, 0, 0)
+			continue
+		case char < ascii85Offset || char > ascii85Offset+84:
+			return []byte{}, &DecodeError{
+				Offset: int64(offset),
+				Reason: fmt.Sprintf("invalid ascii85 character: %s", []byte{char}),
+			}
+		}
+
+		digits[count] = char - ascii85Offset
+		count++
+		if count < len(digits) {
+			continue
+		}
+
+		var ok bool
+		if out, ok = appendBase85Group(out, digits, 4); !ok {
+			return []byte{}, &DecodeError{Offset: int64(offset), Reason: "group is larger than 32 bits"}
+		}
+		count = 0
+	}
+
+	if count != 0 {
+		// one character on its own isn't a whole byte
+		if count == 1 {
+			return []byte{}, &DecodeError{Offset: int64(len(text)), Reason: "input is truncated"}
+		}
+
+		// fill the missing digits with the highest value, so that rounding
+		// down gives us the original bytes
+		for i := count; i < len(digits); i++ {
+			digits[i] = 84
+		}
+
+		var ok bool
+		if out, ok = appendBase85Group(out, digits, count-1); !ok {
+			return []byte{}, &DecodeError{Offset: int64(len(text)), Reason: "group is larger than 32 bits"}
+		}
+	}
+
+	return out, nil
+}
+
+// Z85Encode encodes a byte slice into a Z85 byte slice. Z85 has no padding,
+// so the input must be a multiple of 4 bytes.
+func Z85Encode(text []byte) ([]byte, error) {
+	if len(text)%4 != 0 {
+		return []byte{}, fmt.Errorf("input must be a multiple of 4 bytes: %d", len(text))
+	}
+
+	// 5 characters out for every 4 bytes in
+	out := make([]byte, 0, len(text)/4*5)
+
+	for i := 0; i < len(text); i += 4 {
+		digits := base85Digits(text[i : i+4])
+		for _, digit := range digits {
+			out = append(out, z85Alphabet[digit])
+		}
+	}
+
+	return out, nil
+}
+
+// Z85Decode decodes a Z85 byte slice into a byte slice. Whitespace is
+// ignored. Malformed input is returned as a *DecodeError with the offset of
+// the problem.
+func Z85Decode(text []byte) ([]byte, error) {
+	// 4 bytes out for every 5 bytes in
+	out := make([]byte, 0, len(text)/5*4)
+
+	var (
+		digits [5]byte // digits of the group being decoded
+		count  int     // number of digits in the group
+	)
+	for offset, char := range text {
+		if isWhitespace(char) {
+			continue
+		}
+
+		digit := z85DecodeMap[char]
+		if digit == invalidChar {
+			return []byte{}, &DecodeError{
+				Offset: int64(offset),
+				Reason: fmt.Sprintf("invalid z85 character: %s", []byte{char}),
+			}
+		}
+
+		digits[count] = digit
+		count++
+		if count < len(digits) {
+			continue
+		}
+
+		var ok bool
+		if out, ok = appendBase85Group(out, digits, 4); !ok {
+			return []byte{}, &DecodeError{Offset: int64(offset), Reason: "group is larger than 32 bits"}
+		}
+		count = 0
+	}
+
+	// there's no padding, so the input must be a whole number of groups
+	if count != 0 {
+		return []byte{}, &DecodeError{Offset: int64(len(text)), Reason: "input is truncated"}
+	}
+
+	return out, nil
+}
@@ -0,0 +1,114 @@
+package cryptopals_test
+
+import (
+	"encoding/ascii85"
+
+	. "github.com/dcarley/cryptopals"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Base85", func() {
+	Describe("Ascii85", func() {
+		DescribeTable("round trips",
+			func(input string) {
+				expected := make([]byte, ascii85.MaxEncodedLen(len(input)))
+				expected = expected[:ascii85.Encode(expected, []byte(input))]
+
+				encoded := Ascii85Encode([]byte(input))
+				Expect(encoded).To(Equal(expected))
+
+				decoded, err := Ascii85Decode(encoded)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(decoded).To(Equal([]byte(input)))
+			},
+			Entry("empty", ""),
+			Entry("1 byte", "h"),
+			Entry("2 bytes", "he"),
+			Entry("3 bytes", "hel"),
+			Entry("4 bytes", "hell"),
+			Entry("sentence", "hello gophers!"),
+			Entry("group of zeros", "\x00\x00\x00\x00hello\x00\x00\x00\x00"),
+			Entry("partial group of zeros", "hell\x00\x00"),
+			Entry("largest values", "\xff\xff\xff\xff\xff"),
+		)
+
+		It("should decode with delimiters and whitespace", func() {
+			decoded, err := Ascii85Decode([]byte("<~BOu!rD]i\\2E\n+ig'F!1~>\n"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(decoded).To(Equal([]byte("hello gophers!")))
+		})
+
+		DescribeTable("errors",
+			func(encoded, expected string) {
+				decoded, err := Ascii85Decode([]byte(encoded))
+				Expect(err).To(MatchError(expected))
+				Expect(decoded).To(Equal([]byte{}))
+			},
+			Entry("invalid character",
+				"BOu!rD]j7v", "invalid ascii85 character: v at offset 9",
+			),
+			Entry("z in the middle of a group",
+				"BOuzrD]j7B", "unexpected z in group at offset 3",
+			),
+			Entry("group larger than 32 bits",
+				"uuuuu", "group is larger than 32 bits at offset 4",
+			),
+			Entry("truncated input",
+				"BOu!rD", "input is truncated at offset 6",
+			),
+			Entry("data after end delimiter",
+				"<~BOu!r~>D", "unexpected data after end delimiter at offset 9",
+			),
+		)
+	})
+
+	Describe("Z85", func() {
+		It("should encode the example from the specification", func() {
+			encoded, err := Z85Encode([]byte{0x86, 0x4F, 0xD2, 0x6F, 0xB5, 0x59, 0xF7, 0x5B})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(encoded).To(Equal([]byte("HelloWorld")))
+		})
+
+		DescribeTable("round trips",
+			func(input string) {
+				encoded, err := Z85Encode([]byte(input))
+				Expect(err).ToNot(HaveOccurred())
+
+				decoded, err := Z85Decode(encoded)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(decoded).To(Equal([]byte(input)))
+			},
+			Entry("empty", ""),
+			Entry("4 bytes", "hell"),
+			Entry("sentence", "hello gophers!!!"),
+			Entry("zeros", "\x00\x00\x00\x00"),
+			Entry("largest values", "\xff\xff\xff\xff"),
+		)
+
+		It("should return an error when encoding a partial group", func() {
+			encoded, err := Z85Encode([]byte("hello"))
+			Expect(err).To(MatchError("input must be a multiple of 4 bytes: 5"))
+			Expect(encoded).To(Equal([]byte{}))
+		})
+
+		DescribeTable("decoding errors",
+			func(encoded, expected string) {
+				decoded, err := Z85Decode([]byte(encoded))
+				Expect(err).To(MatchError(expected))
+				Expect(decoded).To(Equal([]byte{}))
+			},
+			Entry("invalid character",
+				"Hello,orld", "invalid z85 character: , at offset 5",
+			),
+			Entry("group larger than 32 bits",
+				"#####", "group is larger than 32 bits at offset 4",
+			),
+			Entry("truncated input",
+				"HelloWor", "input is truncated at offset 8",
+			),
+		)
+	})
+})
@@ -0,0 +1,723 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/dcarley/cryptopals"
+)
+
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+// command is a subcommand of the tool. run is given the flag set after the
+// common flags have been added, so that it can add its own.
+type command struct {
+	args    string // positional arguments, other than files
+	summary string
+	flags   commonFlags
+	run     func(flags *flag.FlagSet, env *env) error
+}
+
+// commonFlags are the flags that are shared between commands. They're only
+// added to the commands that use them, so that giving one to a command that
+// would ignore it is a usage error.
+type commonFlags int
+
+const (
+	jsonFlag commonFlags = 1 << iota // -json
+	inFlag                           // -in
+	outFlag                          // -out
+)
+
+var commands = map[string]command{
+	"hex": {
+		args:    "encode|decode",
+		summary: "convert to or from hex",
+		run:     runHex,
+	},
+	"b64": {
+		args:    "encode|decode",
+		summary: "convert to or from base64",
+		run:     runBase64,
+	},
+	"xor": {
+		summary: "XOR against a fixed or repeating key",
+		flags:   inFlag | outFlag,
+		run:     runXOR,
+	},
+	"crack-xor": {
+		summary: "find the single or multi byte key that was XORed against",
+		flags:   inFlag | jsonFlag,
+		run:     runCrackXOR,
+	},
+	"ecb-encrypt": {
+		summary: "encrypt AES in ECB mode",
+		flags:   inFlag | outFlag,
+		run:     runECBEncrypt,
+	},
+	"ecb-decrypt": {
+		summary: "decrypt AES in ECB mode",
+		flags:   inFlag | outFlag,
+		run:     runECBDecrypt,
+	},
+	"detect-ecb": {
+		summary: "find lines that have been encrypted in ECB mode",
+		flags:   inFlag | jsonFlag,
+		run:     runDetectECB,
+	},
+	"detect-xor": {
+		summary: "find lines that have been XORed against a single byte",
+		flags:   inFlag | jsonFlag,
+		run:     runDetectXOR,
+	},
+}
+
+// argsUsage returns the positional arguments for usage, if any.
+func (c command) argsUsage() string {
+	if c.args == "" {
+		return ""
+	}
+
+	return c.args + " "
+}
+
+// errUsage is returned by commands when they have been given the wrong
+// arguments, so that usage can be printed.
+var errUsage = errors.New("invalid arguments")
+
+// env is the environment that a command runs in, so that it can be tested
+// without a real process.
+type env struct {
+	args   []string
+	stdin  io.Reader
+	stdout io.Writer
+	json   bool
+	output string
+	input  string
+}
+
+// run is the real main, which returns an exit code instead of exiting.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "help" {
+		printUsage(stderr)
+		return exitUsage
+	}
+
+	name := args[0]
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command: %s\n", name)
+		printUsage(stderr)
+		return exitUsage
+	}
+
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	// usage is printed below, after any error from parsing
+	flags.Usage = func() {}
+	e := &env{
+		args:   args[1:],
+		stdin:  stdin,
+		stdout: stdout,
+		input:  "raw",
+		output: "raw",
+	}
+	if cmd.flags&jsonFlag != 0 {
+		flags.BoolVar(&e.json, "json", false, "print results as JSON")
+	}
+	if cmd.flags&inFlag != 0 {
+		flags.StringVar(&e.input, "in", "raw", "encoding of the input: raw, hex, b64 or auto")
+	}
+	if cmd.flags&outFlag != 0 {
+		flags.StringVar(&e.output, "out", "raw", "encoding of the output: raw, hex or b64")
+	}
+
+	if err := cmd.run(flags, e); err != nil {
+		if err == errUsage || err == flag.ErrHelp {
+			fmt.Fprintf(stderr, "usage: cryptopals %s [flags] %s[file ...]\n%s\n", name, cmd.argsUsage(), cmd.summary)
+			flags.PrintDefaults()
+			return exitUsage
+		}
+
+		fmt.Fprintf(stderr, "%s: %s\n", name, err)
+		return exitError
+	}
+
+	return exitOK
+}
+
+// printUsage lists all of the commands.
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "usage: cryptopals <command> [flags] [file ...]")
+	fmt.Fprintln(w, "commands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-12s %s\n", name, commands[name].summary)
+	}
+}
+
+// parse parses the command line, leaving the remaining arguments in
+// e.args.
+func (e *env) parse(flags *flag.FlagSet) error {
+	if err := flags.Parse(e.args); err != nil {
+		// the flag package has already printed what was wrong
+		if err == flag.ErrHelp {
+			return err
+		}
+
+		return errUsage
+	}
+	e.args = flags.Args()
+
+	return nil
+}
+
+// readInput reads all of the files given as arguments, or stdin if there
+// are none.
+func (e *env) readInput() ([]byte, error) {
+	if len(e.args) == 0 {
+		return ioutil.ReadAll(e.stdin)
+	}
+
+	var buf bytes.Buffer
+	for _, path := range e.args {
+		file, err := os.Open(path)
+		if err != nil {
+			return []byte{}, err
+		}
+
+		_, err = buf.ReadFrom(file)
+		file.Close()
+		if err != nil {
+			return []byte{}, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// readDecodedInput reads the input and decodes it using the encoding chosen
+// by the -in flag.
+func (e *env) readDecodedInput() ([]byte, error) {
+	text, err := e.readInput()
+	if err != nil {
+		return []byte{}, err
+	}
+
+	return decodeInput(e.input, text)
+}
+
+// decodeInput decodes text using a named encoding. Whitespace is ignored for
+// every encoding except raw.
+func decodeInput(encoding string, text []byte) ([]byte, error) {
+	switch encoding {
+	case "raw":
+		return text, nil
+	case "hex":
+		return cryptopals.HexDecode(stripSpace(text))
+	case "b64":
+		return cryptopals.Base64Decode(text)
+	case "auto":
+		out, _, err := cryptopals.DecodeAny(text)
+		return out, err
+	}
+
+	return []byte{}, fmt.Errorf("unknown input encoding: %s", encoding)
+}
+
+// decodeKey decodes a key given as a flag using the same encoding as the
+// input, chosen by the -in flag, so that keys don't need to be printable.
+func (e *env) decodeKey(key string) ([]byte, error) {
+	out, err := decodeInput(e.input, []byte(key))
+	if err != nil {
+		return []byte{}, fmt.Errorf("key: %s", err)
+	}
+
+	return out, nil
+}
+
+// stripSpace returns a copy of text with all whitespace removed.
+func stripSpace(text []byte) []byte {
+	return bytes.Join(bytes.FieldsFunc(text, unicode.IsSpace), nil)
+}
+
+// writeOutput writes text using the encoding chosen by the -out flag.
+func (e *env) writeOutput(text []byte) error {
+	switch e.output {
+	case "raw":
+	case "hex":
+		text = append(cryptopals.HexEncode(text), '\n')
+	case "b64":
+		text = append(cryptopals.Base64Encode(text), '\n')
+	default:
+		return fmt.Errorf("unknown output encoding: %s", e.output)
+	}
+
+	_, err := e.stdout.Write(text)
+	return err
+}
+
+// writeJSON writes v as indented JSON.
+func (e *env) writeJSON(v interface{}) error {
+	encoder := json.NewEncoder(e.stdout)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(v)
+}
+
+// runCodec implements hex and b64, which both have encode and decode
+// actions.
+func runCodec(e *env, encode, decode func([]byte) ([]byte, error)) error {
+	if len(e.args) == 0 {
+		return errUsage
+	}
+
+	action := e.args[0]
+	e.args = e.args[1:]
+
+	text, err := e.readInput()
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case "encode":
+		out, err := encode(text)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.stdout.Write(append(out, '\n'))
+		return err
+	case "decode":
+		out, err := decode(text)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.stdout.Write(out)
+		return err
+	}
+
+	return errUsage
+}
+
+func runHex(flags *flag.FlagSet, e *env) error {
+	if err := e.parse(flags); err != nil {
+		return err
+	}
+
+	return runCodec(e,
+		func(text []byte) ([]byte, error) {
+			return cryptopals.HexEncode(text), nil
+		},
+		func(text []byte) ([]byte, error) {
+			return cryptopals.HexDecode(stripSpace(text))
+		},
+	)
+}
+
+func runBase64(flags *flag.FlagSet, e *env) error {
+	url := flags.Bool("url", false, "use the URL and filename safe alphabet")
+	raw := flags.Bool("raw", false, "don't use padding")
+	wrap := flags.Int("wrap", 0, "wrap encoded lines after this many characters, such as 64 or 76")
+	if err := e.parse(flags); err != nil {
+		return err
+	}
+
+	enc := cryptopals.StdBase64
+	switch {
+	case *url && *raw:
+		enc = cryptopals.RawURLBase64
+	case *url:
+		enc = cryptopals.URLBase64
+	case *raw:
+		enc = cryptopals.RawStdBase64
+	}
+
+	return runCodec(e,
+		func(text []byte) ([]byte, error) {
+			var out bytes.Buffer
+			encoder := enc.NewEncoder(&out, *wrap)
+			if _, err := encoder.Write(text); err != nil {
+				return []byte{}, err
+			}
+			if err := encoder.Close(); err != nil {
+				return []byte{}, err
+			}
+
+			return bytes.TrimSuffix(out.Bytes(), []byte{'\n'}), nil
+		},
+		enc.Decode,
+	)
+}
+
+func runXOR(flags *flag.FlagSet, e *env) error {
+	key := flags.String("key", "", "key to XOR against, decoded using the -in encoding")
+	fixed := flags.Bool("fixed", false, "require the key to be the same size as the input")
+	if err := e.parse(flags); err != nil {
+		return err
+	}
+	if *key == "" {
+		return errUsage
+	}
+
+	keyBytes, err := e.decodeKey(*key)
+	if err != nil {
+		return err
+	}
+
+	text, err := e.readDecodedInput()
+	if err != nil {
+		return err
+	}
+
+	var out []byte
+	if *fixed {
+		out, err = cryptopals.FixedKeyXOR(text, keyBytes)
+	} else {
+		out, err = cryptopals.RepeatingKeyXOR(text, keyBytes)
+	}
+	if err != nil {
+		return err
+	}
+
+	return e.writeOutput(out)
+}
+
+// scorerFlags adds the -model and -scorer flags, and returns a function to
+// make a scorer from them after they've been parsed.
+func scorerFlags(flags *flag.FlagSet) func() (cryptopals.Scorer, error) {
+	modelPath := flags.String("model", "", "score plaintext using a language model file instead of letter counts")
+	scorerSpec := flags.String("scorer", "", "score plaintext using built-in scorers, optionally weighted: "+
+		strings.Join(cryptopals.ScorerNames(), ", "))
+
+	return func() (cryptopals.Scorer, error) {
+		switch {
+		case *modelPath != "" && *scorerSpec != "":
+			return nil, errUsage
+		case *modelPath != "":
+			model, err := cryptopals.LoadLanguageModel(*modelPath)
+			if err != nil {
+				return nil, err
+			}
+			return model.Scorer(), nil
+		case *scorerSpec != "":
+			return cryptopals.ParseScorer(*scorerSpec)
+		}
+
+		return cryptopals.ETAOINScorer, nil
+	}
+}
+
+// keyScoreJSON is how a KeyScore is printed as JSON, with the key in both
+// text and hex because it might not be printable.
+type keyScoreJSON struct {
+	Score      float64  `json:"score"`
+	Normalised *float64 `json:"normalised,omitempty"`
+	Key        string   `json:"key"`
+	KeyHex     string   `json:"key_hex"`
+	Text       string   `json:"text"`
+}
+
+func runCrackXOR(flags *flag.FlagSet, e *env) error {
+	multi := flags.Bool("multi", false, "find a multi byte key instead of a single byte key")
+	newScorer := scorerFlags(flags)
+	top := flags.Int("top", 0, "print the top N candidate keys, trying all 256 values for each byte")
+	if err := e.parse(flags); err != nil {
+		return err
+	}
+
+	scorer, err := newScorer()
+	if err != nil {
+		return err
+	}
+
+	text, err := e.readDecodedInput()
+	if err != nil {
+		return err
+	}
+
+	if *top > 0 {
+		return printRanking(e, text, scorer, *multi, *top)
+	}
+
+	var score cryptopals.KeyScore
+	if *multi {
+		score, err = cryptopals.BruteForceMultiByteXORWithScorer(text, scorer)
+	} else {
+		score, err = cryptopals.BruteForceSingleByteXORWithScorer(text, scorer)
+	}
+	if err != nil {
+		return err
+	}
+
+	if e.json {
+		return e.writeJSON(keyScoreJSON{
+			Score:  score.Score,
+			Key:    string(score.Key),
+			KeyHex: string(cryptopals.HexEncode(score.Key)),
+			Text:   string(score.Text),
+		})
+	}
+
+	_, err = fmt.Fprintf(e.stdout, "score: %g\nkey: %q\nkey (hex): %s\n\n%s\n",
+		score.Score, score.Key, cryptopals.HexEncode(score.Key), score.Text,
+	)
+	return err
+}
+
+// rankingJSON is how a Ranking is printed as JSON.
+type rankingJSON struct {
+	Confidence float64        `json:"confidence"`
+	Candidates []keyScoreJSON `json:"candidates"`
+}
+
+// printRanking implements crack-xor with -top.
+func printRanking(e *env, text []byte, scorer cryptopals.Scorer, multi bool, limit int) error {
+	var (
+		ranking cryptopals.Ranking
+		err     error
+	)
+	if multi {
+		ranking, err = cryptopals.RankMultiByteXOR(text, scorer, limit)
+	} else {
+		ranking, err = cryptopals.RankSingleByteXOR(text, scorer, limit)
+	}
+	if err != nil {
+		return err
+	}
+
+	if e.json {
+		out := rankingJSON{Confidence: ranking.Confidence}
+		for _, candidate := range ranking.Candidates {
+			normalised := candidate.Normalised
+			out.Candidates = append(out.Candidates, keyScoreJSON{
+				Score:      candidate.Score,
+				Normalised: &normalised,
+				Key:        string(candidate.Key),
+				KeyHex:     string(cryptopals.HexEncode(candidate.Key)),
+				Text:       string(candidate.Text),
+			})
+		}
+
+		return e.writeJSON(out)
+	}
+
+	if _, err := fmt.Fprintf(e.stdout, "confidence: %.3f\n", ranking.Confidence); err != nil {
+		return err
+	}
+	for i, candidate := range ranking.Candidates {
+		_, err := fmt.Fprintf(e.stdout, "\n%d. score: %g (%.3f)\nkey: %q\nkey (hex): %s\n\n%s\n",
+			i+1, candidate.Score, candidate.Normalised, candidate.Key, cryptopals.HexEncode(candidate.Key), candidate.Text,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func runECBEncrypt(flags *flag.FlagSet, e *env) error {
+	key := flags.String("key", "", "AES key, which must be 16, 24 or 32 bytes, decoded using the -in encoding")
+	if err := e.parse(flags); err != nil {
+		return err
+	}
+	if *key == "" {
+		return errUsage
+	}
+
+	keyBytes, err := e.decodeKey(*key)
+	if err != nil {
+		return err
+	}
+
+	text, err := e.readDecodedInput()
+	if err != nil {
+		return err
+	}
+
+	out, err := cryptopals.EncryptAESECB(text, keyBytes)
+	if err != nil {
+		return err
+	}
+
+	return e.writeOutput(out)
+}
+
+func runECBDecrypt(flags *flag.FlagSet, e *env) error {
+	key := flags.String("key", "", "AES key, which must be 16, 24 or 32 bytes, decoded using the -in encoding")
+	if err := e.parse(flags); err != nil {
+		return err
+	}
+	if *key == "" {
+		return errUsage
+	}
+
+	keyBytes, err := e.decodeKey(*key)
+	if err != nil {
+		return err
+	}
+
+	text, err := e.readDecodedInput()
+	if err != nil {
+		return err
+	}
+
+	out, err := cryptopals.DecryptAESECB(text, keyBytes)
+	if err != nil {
+		return err
+	}
+
+	return e.writeOutput(out)
+}
+
+// ecbLineJSON is how a line detected by detect-ecb is printed as JSON.
+type ecbLineJSON struct {
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+func runDetectECB(flags *flag.FlagSet, e *env) error {
+	// lines in the challenge 8 fixture are hex encoded
+	flags.Lookup("in").DefValue = "hex"
+	flags.Set("in", "hex")
+	if err := e.parse(flags); err != nil {
+		return err
+	}
+
+	text, err := e.readInput()
+	if err != nil {
+		return err
+	}
+
+	detected := []ecbLineJSON{}
+	// allow lines as long as detect-xor does
+	scanner := bufio.NewScanner(bytes.NewReader(text))
+	scanner.Buffer(nil, cryptopals.DefaultMaxLineSize)
+	lineNum := 1
+	for ; scanner.Scan(); lineNum++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		decoded, err := decodeInput(e.input, line)
+		if err != nil {
+			return fmt.Errorf("line %d: %s", lineNum, err)
+		}
+
+		if cryptopals.DetectECB(decoded) {
+			detected = append(detected, ecbLineJSON{Line: lineNum, Text: string(line)})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return fmt.Errorf("line %d is longer than %d bytes", lineNum, cryptopals.DefaultMaxLineSize)
+		}
+		return err
+	}
+
+	if e.json {
+		return e.writeJSON(detected)
+	}
+
+	for _, line := range detected {
+		if _, err := fmt.Fprintf(e.stdout, "%d: %s\n", line.Line, line.Text); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// xorLineJSON is how a line detected by detect-xor is printed as JSON.
+type xorLineJSON struct {
+	Line int `json:"line"`
+	keyScoreJSON
+}
+
+func runDetectXOR(flags *flag.FlagSet, e *env) error {
+	// lines in the challenge 4 fixture are hex encoded
+	flags.Lookup("in").DefValue = "hex"
+	flags.Set("in", "hex")
+	newScorer := scorerFlags(flags)
+	top := flags.Int("top", 1, "print the N most likely lines")
+	if err := e.parse(flags); err != nil {
+		return err
+	}
+	if *top < 1 {
+		return errUsage
+	}
+
+	scorer, err := newScorer()
+	if err != nil {
+		return err
+	}
+
+	text, err := e.readInput()
+	if err != nil {
+		return err
+	}
+
+	scores, err := cryptopals.DetectSingleByteXOR(context.Background(), bytes.NewReader(text), cryptopals.DetectXOROptions{
+		Decode: func(line []byte) ([]byte, error) {
+			return decodeInput(e.input, line)
+		},
+		Scorer: scorer,
+		Limit:  *top,
+	})
+	if err != nil {
+		return err
+	}
+
+	if e.json {
+		detected := make([]xorLineJSON, 0, len(scores))
+		for _, score := range scores {
+			detected = append(detected, xorLineJSON{
+				Line: score.Line,
+				keyScoreJSON: keyScoreJSON{
+					Score:  score.Score,
+					Key:    string(score.Key),
+					KeyHex: string(cryptopals.HexEncode(score.Key)),
+					Text:   string(score.Text),
+				},
+			})
+		}
+
+		return e.writeJSON(detected)
+	}
+
+	for _, score := range scores {
+		_, err := fmt.Fprintf(e.stdout, "%d: score: %g key: %q text: %q\n",
+			score.Line, score.Score, score.Key, score.Text,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,339 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dcarley/cryptopals"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Commands", func() {
+	const fixtures = "../../fixtures/"
+
+	var stdout, stderr bytes.Buffer
+
+	BeforeEach(func() {
+		stdout.Reset()
+		stderr.Reset()
+	})
+
+	// runWithStdin runs a command with some text on stdin and returns the
+	// exit code.
+	runWithStdin := func(stdin string, args ...string) int {
+		return run(args, strings.NewReader(stdin), &stdout, &stderr)
+	}
+
+	DescribeTable("encoding",
+		func(stdin string, args []string, expected string) {
+			Expect(runWithStdin(stdin, args...)).To(Equal(exitOK), stderr.String())
+			Expect(stdout.String()).To(Equal(expected))
+		},
+		Entry("hex encode", "hello gopher", []string{"hex", "encode"}, "68656c6c6f20676f70686572\n"),
+		Entry("hex decode", "68656c6c6f20\n676f70686572\n", []string{"hex", "decode"}, "hello gopher"),
+		Entry("b64 encode", "hello gophers", []string{"b64", "encode"}, "aGVsbG8gZ29waGVycw==\n"),
+		Entry("b64 encode URL safe unpadded", "\xfb\xff", []string{"b64", "-url", "-raw", "encode"}, "-_8\n"),
+		Entry("b64 encode wrapped", "hello gopher", []string{"b64", "-wrap", "8", "encode"}, "aGVsbG8g\nZ29waGVy\n"),
+		Entry("b64 decode", "aGVsbG8g\r\nZ29waGVycw==\r\n", []string{"b64", "decode"}, "hello gophers"),
+	)
+
+	Describe("xor", func() {
+		It("should solve challenge 5", func() {
+			Expect(runWithStdin(
+				"Burning 'em, if you ain't quick and nimble\nI go crazy when I hear a cymbal",
+				"xor", "-key", "ICE", "-out", "hex",
+			)).To(Equal(exitOK))
+			Expect(stdout.String()).To(Equal("0b3637272a2b2e63622c2e69692a23693a2a3c6324202d623d63343c2a26226324272765272a282b2f20430a652e2c652a3124333a653e2b2027630c692b20283165286326302e27282f\n"))
+		})
+
+		It("should solve challenge 2 with a fixed key", func() {
+			Expect(runWithStdin(
+				"1c0111001f010100061a024b53535009181c",
+				"xor", "-fixed", "-in", "hex", "-key", "686974207468652062756c6c277320657965",
+			)).To(Equal(exitOK))
+			Expect(stdout.String()).To(Equal("the kid don't play"))
+		})
+
+		It("should fail when a fixed key is the wrong size", func() {
+			Expect(runWithStdin("12345678", "xor", "-fixed", "-key", "1234")).To(Equal(exitError))
+			Expect(stderr.String()).To(Equal("xor: text and key must be same size: 8 != 4\n"))
+		})
+
+		It("should require a key", func() {
+			Expect(runWithStdin("", "xor")).To(Equal(exitUsage))
+			Expect(stderr.String()).To(HavePrefix("usage: cryptopals xor [flags] [file ...]\n"))
+		})
+	})
+
+	Describe("crack-xor", func() {
+		It("should solve challenge 3", func() {
+			Expect(runWithStdin(
+				"1b37373331363f78151b7f2b783431333d78397828372d363c78373e783a393b3736",
+				"crack-xor", "-in", "hex",
+			)).To(Equal(exitOK))
+			Expect(stdout.String()).To(HaveSuffix("\n\nCooking MC's like a pound of bacon\n"))
+			Expect(stdout.String()).To(ContainSubstring(`key: "X"`))
+		})
+
+		It("should solve challenge 6 from a file as JSON", func() {
+			Expect(run(
+				[]string{"crack-xor", "-multi", "-in", "b64", "-json", fixtures + "s1c6"},
+				strings.NewReader(""), &stdout, &stderr,
+			)).To(Equal(exitOK), stderr.String())
+
+			plain, err := ioutil.ReadFile(fixtures + "s1c6.plain")
+			Expect(err).ToNot(HaveOccurred())
+
+			var result map[string]interface{}
+			Expect(json.Unmarshal(stdout.Bytes(), &result)).To(Succeed())
+			Expect(result["key"]).To(Equal("Terminator X: Bring the noise"))
+			Expect(result["key_hex"]).To(Equal("5465726d696e61746f7220583a204272696e6720746865206e6f697365"))
+			Expect(result["text"]).To(Equal(string(plain)))
+		})
+		It("should solve challenge 3 with a language model", func() {
+			dir, err := ioutil.TempDir("", "cryptopals")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.RemoveAll(dir)
+
+			plain, err := os.Open(fixtures + "s1c6.plain")
+			Expect(err).ToNot(HaveOccurred())
+			defer plain.Close()
+
+			model, err := cryptopals.TrainLanguageModel(plain)
+			Expect(err).ToNot(HaveOccurred())
+
+			path := filepath.Join(dir, "english.model")
+			file, err := os.Create(path)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = model.WriteTo(file)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(file.Close()).To(Succeed())
+
+			Expect(runWithStdin(
+				"1b37373331363f78151b7f2b783431333d78397828372d363c78373e783a393b3736",
+				"crack-xor", "-in", "hex", "-model", path,
+			)).To(Equal(exitOK), stderr.String())
+			Expect(stdout.String()).To(HaveSuffix("\n\nCooking MC's like a pound of bacon\n"))
+		})
+
+		It("should solve challenge 3 with a named scorer", func() {
+			Expect(runWithStdin(
+				"1b37373331363f78151b7f2b783431333d78397828372d363c78373e783a393b3736",
+				"crack-xor", "-in", "hex", "-scorer", "chi2",
+			)).To(Equal(exitOK), stderr.String())
+			Expect(stdout.String()).To(HaveSuffix("\n\nCooking MC's like a pound of bacon\n"))
+		})
+
+		It("should print the top candidates for challenge 3", func() {
+			Expect(runWithStdin(
+				"1b37373331363f78151b7f2b783431333d78397828372d363c78373e783a393b3736",
+				"crack-xor", "-in", "hex", "-scorer", "chi2", "-top", "3",
+			)).To(Equal(exitOK), stderr.String())
+			Expect(stdout.String()).To(HavePrefix("confidence: "))
+			Expect(stdout.String()).To(ContainSubstring("\n1. score: "))
+			Expect(stdout.String()).To(ContainSubstring("\n3. score: "))
+			Expect(stdout.String()).ToNot(ContainSubstring("\n4. score: "))
+			Expect(stdout.String()).To(ContainSubstring("(1.000)\nkey: \"X\"\nkey (hex): 58\n\nCooking MC's like a pound of bacon\n"))
+		})
+
+		It("should print the top candidates for challenge 6 as JSON", func() {
+			Expect(run(
+				[]string{"crack-xor", "-multi", "-in", "b64", "-json", "-top", "2", fixtures + "s1c6"},
+				strings.NewReader(""), &stdout, &stderr,
+			)).To(Equal(exitOK), stderr.String())
+
+			var result struct {
+				Confidence float64
+				Candidates []map[string]interface{}
+			}
+			Expect(json.Unmarshal(stdout.Bytes(), &result)).To(Succeed())
+			Expect(result.Candidates).To(HaveLen(2))
+			Expect(result.Candidates[0]["key"]).To(Equal("Terminator X: Bring the noise"))
+			Expect(result.Candidates[0]["normalised"]).To(Equal(1.0))
+		})
+
+		It("should fail with a scorer that doesn't exist", func() {
+			Expect(runWithStdin("", "crack-xor", "-scorer", "klingon")).To(Equal(exitError))
+			Expect(stderr.String()).To(Equal("crack-xor: unknown scorer: klingon\n"))
+		})
+
+		It("should not allow a model and a scorer together", func() {
+			Expect(runWithStdin("", "crack-xor", "-model", "english.model", "-scorer", "chi2")).To(Equal(exitUsage))
+		})
+
+		It("should fail with a model that doesn't exist", func() {
+			Expect(runWithStdin("", "crack-xor", "-model", "does-not-exist")).To(Equal(exitError))
+			Expect(stderr.String()).To(Equal("crack-xor: open does-not-exist: no such file or directory\n"))
+		})
+	})
+
+	Describe("ecb-encrypt", func() {
+		It("should encrypt challenge 7", func() {
+			Expect(run(
+				[]string{"ecb-encrypt", "-out", "b64", "-key", "YELLOW SUBMARINE", fixtures + "s1c7.plain"},
+				strings.NewReader(""), &stdout, &stderr,
+			)).To(Equal(exitOK), stderr.String())
+
+			b64, err := ioutil.ReadFile(fixtures + "s1c7")
+			Expect(err).ToNot(HaveOccurred())
+			expected, err := cryptopals.Base64Decode(b64)
+			Expect(err).ToNot(HaveOccurred())
+
+			out, err := cryptopals.Base64Decode(stdout.Bytes())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(out).To(Equal(expected))
+		})
+
+		It("should decode the key using the input encoding", func() {
+			Expect(runWithStdin(
+				"68656c6c6f20676f70686572", "ecb-encrypt", "-in", "hex", "-out", "b64",
+				"-key", "59454c4c4f57205355424d4152494e45",
+			)).To(Equal(exitOK), stderr.String())
+			Expect(stdout.String()).To(Equal("eVr6zWJIisWmxzyLn39p3A==\n"))
+		})
+
+		It("should require a key", func() {
+			Expect(runWithStdin("", "ecb-encrypt")).To(Equal(exitUsage))
+		})
+	})
+
+	Describe("ecb-decrypt", func() {
+		It("should solve challenge 7", func() {
+			Expect(run(
+				[]string{"ecb-decrypt", "-in", "b64", "-key", "WUVMTE9XIFNVQk1BUklORQ==", fixtures + "s1c7"},
+				strings.NewReader(""), &stdout, &stderr,
+			)).To(Equal(exitOK), stderr.String())
+
+			plain, err := ioutil.ReadFile(fixtures + "s1c7.plain")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stdout.Bytes()).To(Equal(plain))
+		})
+
+		It("should fail with an invalid key", func() {
+			Expect(runWithStdin("", "ecb-decrypt", "-key", "short")).To(Equal(exitError))
+			Expect(stderr.String()).To(Equal("ecb-decrypt: crypto/aes: invalid key size 5\n"))
+		})
+
+		It("should decode the key using the input encoding", func() {
+			Expect(runWithStdin("", "ecb-decrypt", "-in", "hex", "-key", "YELLOW_SUBMARINE")).To(Equal(exitError))
+			Expect(stderr.String()).To(Equal("ecb-decrypt: key: invalid hex character: Y at offset 0\n"))
+		})
+
+		It("should fail with a partial block", func() {
+			Expect(runWithStdin("0123456789", "ecb-decrypt", "-key", "YELLOW SUBMARINE")).To(Equal(exitError))
+			Expect(stderr.String()).To(Equal("ecb-decrypt: ciphertext must be a multiple of the block size 16: 10\n"))
+		})
+	})
+
+	Describe("detect-ecb", func() {
+		It("should solve challenge 8", func() {
+			Expect(run(
+				[]string{"detect-ecb", fixtures + "s1c8"},
+				strings.NewReader(""), &stdout, &stderr,
+			)).To(Equal(exitOK), stderr.String())
+			Expect(stdout.String()).To(HavePrefix("133: d880619740a8a19b"))
+			Expect(strings.Count(stdout.String(), "\n")).To(Equal(1))
+		})
+
+		It("should print JSON", func() {
+			Expect(run(
+				[]string{"detect-ecb", "-json", fixtures + "s1c8"},
+				strings.NewReader(""), &stdout, &stderr,
+			)).To(Equal(exitOK), stderr.String())
+
+			var result []map[string]interface{}
+			Expect(json.Unmarshal(stdout.Bytes(), &result)).To(Succeed())
+			Expect(result).To(HaveLen(1))
+			Expect(result[0]["line"]).To(BeEquivalentTo(133))
+		})
+
+		It("should read lines longer than the bufio.Scanner default", func() {
+			line := strings.Repeat("00", 40000)
+			Expect(runWithStdin("0011\n"+line+"\n", "detect-ecb")).To(Equal(exitOK), stderr.String())
+			Expect(stdout.String()).To(Equal("2: " + line + "\n"))
+		})
+
+		It("should fail with the line that is too long", func() {
+			line := strings.Repeat("0", cryptopals.DefaultMaxLineSize+1)
+			Expect(runWithStdin("0011\n"+line+"\n", "detect-ecb")).To(Equal(exitError))
+			Expect(stderr.String()).To(Equal("detect-ecb: line 2 is longer than 1048576 bytes\n"))
+		})
+	})
+
+	Describe("detect-xor", func() {
+		It("should solve challenge 4", func() {
+			Expect(run(
+				[]string{"detect-xor", fixtures + "s1c4"},
+				strings.NewReader(""), &stdout, &stderr,
+			)).To(Equal(exitOK), stderr.String())
+			Expect(stdout.String()).To(HavePrefix("171: score: "))
+			Expect(stdout.String()).To(HaveSuffix(` key: "5" text: "Now that the party is jumping\n"` + "\n"))
+		})
+
+		It("should print the top lines as JSON", func() {
+			Expect(run(
+				[]string{"detect-xor", "-json", "-top", "2", "-scorer", "chi2", fixtures + "s1c4"},
+				strings.NewReader(""), &stdout, &stderr,
+			)).To(Equal(exitOK), stderr.String())
+
+			var result []map[string]interface{}
+			Expect(json.Unmarshal(stdout.Bytes(), &result)).To(Succeed())
+			Expect(result).To(HaveLen(2))
+			Expect(result[0]["line"]).To(BeEquivalentTo(171))
+			Expect(result[0]["key_hex"]).To(Equal("35"))
+			Expect(result[0]["text"]).To(Equal("Now that the party is jumping\n"))
+		})
+
+		It("should fail with the line that can't be decoded", func() {
+			Expect(runWithStdin("abcd\nxyzz\n", "detect-xor")).To(Equal(exitError))
+			Expect(stderr.String()).To(Equal("detect-xor: line 2: invalid hex character: x at offset 0\n"))
+		})
+	})
+
+	Describe("usage", func() {
+		It("should list commands without arguments", func() {
+			Expect(runWithStdin("")).To(Equal(exitUsage))
+			Expect(stderr.String()).To(ContainSubstring("  crack-xor    find the single"))
+		})
+
+		It("should reject unknown commands", func() {
+			Expect(runWithStdin("", "rot13")).To(Equal(exitUsage))
+			Expect(stderr.String()).To(HavePrefix("unknown command: rot13\n"))
+		})
+
+		It("should reject unknown actions", func() {
+			Expect(runWithStdin("", "hex", "reverse")).To(Equal(exitUsage))
+			Expect(stderr.String()).To(HavePrefix("usage: cryptopals hex [flags] encode|decode [file ...]\n"))
+		})
+
+		DescribeTable("should reject flags that the command doesn't use",
+			func(args []string, flag string) {
+				Expect(runWithStdin("", args...)).To(Equal(exitUsage))
+				Expect(stderr.String()).To(HavePrefix("flag provided but not defined: " + flag + "\nusage: cryptopals " + args[0] + " "))
+			},
+			Entry("hex with -json", []string{"hex", "-json", "encode"}, "-json"),
+			Entry("b64 with -in", []string{"b64", "-in", "hex", "decode"}, "-in"),
+			Entry("xor with -json", []string{"xor", "-json", "-key", "ICE"}, "-json"),
+			Entry("ecb-decrypt with -json", []string{"ecb-decrypt", "-json", "-key", "YELLOW SUBMARINE"}, "-json"),
+			Entry("detect-xor with -out", []string{"detect-xor", "-out", "hex"}, "-out"),
+		)
+
+		It("should only list the flags that the command uses", func() {
+			Expect(runWithStdin("", "hex", "-h")).To(Equal(exitUsage))
+			Expect(stderr.String()).ToNot(ContainSubstring("-json"))
+			Expect(stderr.String()).ToNot(ContainSubstring("-in"))
+		})
+
+		It("should report files that don't exist", func() {
+			Expect(runWithStdin("", "hex", "encode", "does-not-exist")).To(Equal(exitError))
+			Expect(stderr.String()).To(Equal("hex: open does-not-exist: no such file or directory\n"))
+		})
+	})
+})
@@ -0,0 +1,18 @@
+// Command cryptopals provides the solutions to the cryptopals challenges as
+// a command-line tool, for use on data outside of the tests.
+//
+// Usage:
+//
+//	cryptopals <command> [flags] [file ...]
+//
+// Input is read from the files, or stdin if there are none. Run a command
+// with -h to see its flags.
+package main
+
+import (
+	"os"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
@@ -0,0 +1,172 @@
+package cryptopals
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Message is one of the two plaintexts in a CribDrag.
+type Message int
+
+const (
+	// MessageOne is the plaintext of the first ciphertext.
+	MessageOne Message = iota
+	// MessageTwo is the plaintext of the second ciphertext.
+	MessageTwo
+)
+
+// CribDrag recovers two plaintexts that have been XORed against the same
+// keystream, which is how a stream cipher is broken when a key or nonce is
+// reused: https://en.wikipedia.org/wiki/Stream_cipher_attacks
+//
+// XORing the ciphertexts together cancels out the keystream and leaves the
+// plaintexts XORed together. XORing a crib, which is a guess at part of one
+// plaintext, against that reveals the other plaintext at the same position.
+// Only the length of the shorter ciphertext can be recovered.
+type CribDrag struct {
+	ciphertexts [2][]byte
+	xor         []byte
+	plaintexts  [2][]byte
+	known       []bool
+}
+
+// CribPosition is the result of trying a crib at an offset.
+type CribPosition struct {
+	Offset int
+	Score  float64
+	// Text is what the other plaintext would be if the crib was there.
+	Text []byte
+}
+
+// NewCribDrag starts recovering the plaintexts of two ciphertexts that have
+// been XORed against the same keystream.
+func NewCribDrag(one, two []byte) (*CribDrag, error) {
+	size := len(one)
+	if len(two) < size {
+		size = len(two)
+	}
+	if size == 0 {
+		return nil, fmt.Errorf("ciphertexts must not be empty: %d, %d", len(one), len(two))
+	}
+
+	xor, err := FixedKeyXOR(one[:size], two[:size])
+	if err != nil {
+		return nil, err
+	}
+
+	return &CribDrag{
+		ciphertexts: [2][]byte{one[:size], two[:size]},
+		xor:         xor,
+		plaintexts:  [2][]byte{make([]byte, size), make([]byte, size)},
+		known:       make([]bool, size),
+	}, nil
+}
+
+// Len returns how many bytes of each plaintext can be recovered.
+func (c *CribDrag) Len() int {
+	return len(c.xor)
+}
+
+// Drag slides crib across the XORed plaintexts and returns what the other
+// plaintext would be at every offset that it fits, highest score first, or
+// by offset when the scores are the same. A nil scorer uses ETAOINScorer.
+func (c *CribDrag) Drag(crib []byte, scorer Scorer) []CribPosition {
+	if scorer == nil {
+		scorer = ETAOINScorer
+	}
+
+	positions := []CribPosition{}
+	for offset := 0; offset+len(crib) <= len(c.xor) && len(crib) > 0; offset++ {
+		text, _ := FixedKeyXOR(c.xor[offset:offset+len(crib)], crib)
+		positions = append(positions, CribPosition{
+			Offset: offset,
+			Score:  scorer.Score(text),
+			Text:   text,
+		})
+	}
+
+	sort.SliceStable(positions, func(i, j int) bool {
+		return positions[i].Score > positions[j].Score
+	})
+
+	return positions
+}
+
+// Lock records that a message contains crib at offset, which recovers the
+// same part of the other message and the keystream. It returns an error if
+// the crib doesn't fit or disagrees with text that has already been locked.
+func (c *CribDrag) Lock(message Message, offset int, crib []byte) error {
+	if message != MessageOne && message != MessageTwo {
+		return fmt.Errorf("unknown message: %d", message)
+	}
+	if offset < 0 {
+		return fmt.Errorf("offset must not be negative: %d", offset)
+	}
+	if offset+len(crib) > len(c.xor) {
+		return fmt.Errorf("crib doesn't fit at offset %d: %d + %d > %d", offset, offset, len(crib), len(c.xor))
+	}
+
+	// check everything before changing anything
+	for i, char := range crib {
+		if c.known[offset+i] && c.plaintexts[message][offset+i] != char {
+			return fmt.Errorf("crib disagrees with locked text at offset %d", offset+i)
+		}
+	}
+
+	other := 1 - message
+	for i, char := range crib {
+		c.plaintexts[message][offset+i] = char
+		c.plaintexts[other][offset+i] = char ^ c.xor[offset+i]
+		c.known[offset+i] = true
+	}
+
+	return nil
+}
+
+// Unlock forgets the recovered text between offset and offset+size, for
+// when a crib turns out to be wrong.
+func (c *CribDrag) Unlock(offset, size int) error {
+	switch {
+	case offset < 0:
+		return fmt.Errorf("offset must not be negative: %d", offset)
+	case size < 0:
+		return fmt.Errorf("size must not be negative: %d", size)
+	case offset+size > len(c.xor):
+		return fmt.Errorf("range doesn't fit at offset %d: %d + %d > %d", offset, offset, size, len(c.xor))
+	}
+
+	for i := offset; i < offset+size; i++ {
+		c.plaintexts[MessageOne][i] = 0
+		c.plaintexts[MessageTwo][i] = 0
+		c.known[i] = false
+	}
+
+	return nil
+}
+
+// Plaintext returns what has been recovered of a message, with unknown in
+// place of the bytes that haven't.
+func (c *CribDrag) Plaintext(message Message, unknown byte) []byte {
+	out := make([]byte, len(c.xor))
+	for i := range out {
+		out[i] = unknown
+		if c.known[i] {
+			out[i] = c.plaintexts[message][i]
+		}
+	}
+
+	return out
+}
+
+// Keystream returns what has been recovered of the keystream, with zeros in
+// place of the bytes that haven't, and which of the bytes are known.
+func (c *CribDrag) Keystream() ([]byte, []bool) {
+	keystream := make([]byte, len(c.xor))
+	for i := range keystream {
+		if c.known[i] {
+			keystream[i] = c.ciphertexts[MessageOne][i] ^ c.plaintexts[MessageOne][i]
+		}
+	}
+
+	return keystream, append([]bool{}, c.known...)
+}
@@ -0,0 +1,116 @@
+package cryptopals_test
+
+import (
+	"crypto/rand"
+
+	. "github.com/dcarley/cryptopals"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CribDrag", func() {
+	var (
+		one, two  []byte
+		keystream []byte
+		drag      *CribDrag
+	)
+
+	BeforeEach(func() {
+		keystream = make([]byte, 64)
+		_, err := rand.Read(keystream)
+		Expect(err).ToNot(HaveOccurred())
+
+		plainOne := []byte("Now that the party is jumping")
+		plainTwo := []byte("With the bass kicked in and the Vegas are pumpin'")
+
+		one, err = FixedKeyXOR(plainOne, keystream[:len(plainOne)])
+		Expect(err).ToNot(HaveOccurred())
+		two, err = FixedKeyXOR(plainTwo, keystream[:len(plainTwo)])
+		Expect(err).ToNot(HaveOccurred())
+
+		drag, err = NewCribDrag(one, two)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should only recover the length of the shorter ciphertext", func() {
+		Expect(drag.Len()).To(Equal(29))
+	})
+
+	It("should error when a ciphertext is empty", func() {
+		_, err := NewCribDrag(one, []byte{})
+		Expect(err).To(MatchError("ciphertexts must not be empty: 29, 0"))
+	})
+
+	Describe("Drag", func() {
+		It("should find where a common word reveals the other plaintext", func() {
+			positions := drag.Drag([]byte(" the "), EnglishBigramScorer)
+			Expect(positions).To(HaveLen(25))
+
+			var offsets []int
+			for _, position := range positions[:2] {
+				offsets = append(offsets, position.Offset)
+			}
+			Expect(offsets).To(ConsistOf(4, 8))
+
+			for _, position := range positions[:2] {
+				switch position.Offset {
+				case 4:
+					Expect(position.Text).To(Equal([]byte("that ")))
+				case 8:
+					Expect(position.Text).To(Equal([]byte(" bass")))
+				}
+			}
+		})
+
+		It("should return no positions for a crib that doesn't fit", func() {
+			Expect(drag.Drag(make([]byte, 30), nil)).To(BeEmpty())
+			Expect(drag.Drag([]byte{}, nil)).To(BeEmpty())
+		})
+	})
+
+	Describe("Lock", func() {
+		It("should rebuild both plaintexts and the keystream", func() {
+			Expect(drag.Lock(MessageTwo, 4, []byte(" the "))).To(Succeed())
+			Expect(drag.Plaintext(MessageOne, '_')).To(Equal([]byte("____that ____________________")))
+			Expect(drag.Plaintext(MessageTwo, '_')).To(Equal([]byte("____ the ____________________")))
+
+			Expect(drag.Lock(MessageOne, 0, []byte("Now that the party is jumping"))).To(Succeed())
+			Expect(drag.Plaintext(MessageTwo, '_')).To(Equal([]byte("With the bass kicked in and t")))
+
+			recovered, known := drag.Keystream()
+			Expect(recovered).To(Equal(keystream[:29]))
+			Expect(known).To(HaveLen(29))
+			Expect(known).ToNot(ContainElement(false))
+		})
+
+		It("should error when a crib disagrees with locked text", func() {
+			Expect(drag.Lock(MessageOne, 9, []byte("the"))).To(Succeed())
+			Expect(drag.Lock(MessageOne, 8, []byte(" Xhe"))).To(MatchError("crib disagrees with locked text at offset 9"))
+			Expect(drag.Plaintext(MessageOne, '_')).To(Equal([]byte("_________the_________________")))
+		})
+
+		It("should error when a crib doesn't fit", func() {
+			Expect(drag.Lock(MessageOne, 27, []byte("ing"))).To(MatchError("crib doesn't fit at offset 27: 27 + 3 > 29"))
+			Expect(drag.Lock(MessageOne, -1, []byte("N"))).To(MatchError("offset must not be negative: -1"))
+			Expect(drag.Lock(Message(2), 0, []byte("N"))).To(MatchError("unknown message: 2"))
+		})
+	})
+
+	Describe("Unlock", func() {
+		It("should forget recovered text", func() {
+			Expect(drag.Lock(MessageOne, 0, []byte("Now that"))).To(Succeed())
+			Expect(drag.Unlock(3, 5)).To(Succeed())
+			Expect(drag.Plaintext(MessageOne, '?')).To(Equal([]byte("Now??????????????????????????")))
+
+			_, known := drag.Keystream()
+			Expect(known[:4]).To(Equal([]bool{true, true, true, false}))
+		})
+
+		It("should error when the range doesn't fit", func() {
+			Expect(drag.Unlock(20, 10)).To(MatchError("range doesn't fit at offset 20: 20 + 10 > 29"))
+			Expect(drag.Unlock(-1, 2)).To(MatchError("offset must not be negative: -1"))
+			Expect(drag.Unlock(2, -1)).To(MatchError("size must not be negative: -1"))
+		})
+	})
+})
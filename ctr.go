@@ -0,0 +1,161 @@
+package cryptopals
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// CTRLayout describes how the block that's encrypted to make each block of
+// CTR keystream is made from a nonce and a block counter. The nonce comes
+// first, followed by the counter, and together they must fill a block.
+type CTRLayout struct {
+	NonceSize   int
+	CounterSize int // 2, 4 or 8 bytes
+	Order       binary.ByteOrder
+}
+
+var (
+	// CTR64LE is the layout used by the cryptopals challenges, with a
+	// 64-bit nonce and a 64-bit block counter, both little-endian.
+	CTR64LE = CTRLayout{NonceSize: 8, CounterSize: 8, Order: binary.LittleEndian}
+	// CTR96BE is the layout used by AES-GCM, with a 96-bit nonce and a
+	// 32-bit big-endian block counter.
+	CTR96BE = CTRLayout{NonceSize: 12, CounterSize: 4, Order: binary.BigEndian}
+)
+
+// ctrLayouts are the layouts that can be chosen by name in a pipeline spec.
+var ctrLayouts = map[string]CTRLayout{
+	"64le": CTR64LE,
+	"96be": CTR96BE,
+}
+
+// putCounter writes a block counter in the layout's size and byte order.
+func (l CTRLayout) putCounter(dst []byte, counter uint64) {
+	switch l.CounterSize {
+	case 2:
+		l.Order.PutUint16(dst, uint16(counter))
+	case 4:
+		l.Order.PutUint32(dst, uint32(counter))
+	case 8:
+		l.Order.PutUint64(dst, counter)
+	}
+}
+
+// validate returns an error unless the layout fills a block of blockSize.
+func (l CTRLayout) validate(blockSize int) error {
+	switch {
+	case l.CounterSize != 2 && l.CounterSize != 4 && l.CounterSize != 8:
+		return fmt.Errorf("counter must be 2, 4 or 8 bytes: %d", l.CounterSize)
+	case l.NonceSize < 0:
+		return fmt.Errorf("nonce size must not be negative: %d", l.NonceSize)
+	case l.NonceSize+l.CounterSize != blockSize:
+		return fmt.Errorf("nonce and counter must be same size as block: %d + %d != %d",
+			l.NonceSize, l.CounterSize, blockSize)
+	case l.Order == nil:
+		return fmt.Errorf("counter byte order must be set")
+	}
+
+	return nil
+}
+
+// CTRStream is a CTR mode cipher.Stream with a configurable layout for the
+// nonce and counter, where the counter counts blocks from 0. Unlike
+// NewCTR, it's also an io.Seeker, which moves to any byte offset in the
+// stream without XORing anything, so a message can be decrypted from the
+// middle, or the counter started somewhere other than 0.
+type CTRStream struct {
+	block     cipher.Block
+	layout    CTRLayout
+	counter   []byte // nonce followed by the counter for keystream
+	keystream []byte
+	index     int64 // block of keystream that's been made, or -1
+	offset    int64
+	limit     int64 // offset where the counter runs out, or 0 if it doesn't
+}
+
+// NewCTRStream returns a CTRStream which starts at the beginning of the
+// stream. The nonce must be the size given by the layout.
+func NewCTRStream(block cipher.Block, nonce []byte, layout CTRLayout) (*CTRStream, error) {
+	blockSize := block.BlockSize()
+	if err := layout.validate(blockSize); err != nil {
+		return nil, err
+	}
+	if len(nonce) != layout.NonceSize {
+		return nil, fmt.Errorf("nonce must be %d bytes: %d", layout.NonceSize, len(nonce))
+	}
+
+	var limit int64
+	if layout.CounterSize < 8 {
+		limit = int64(1) << (8 * layout.CounterSize) * int64(blockSize)
+	}
+
+	counter := make([]byte, blockSize)
+	copy(counter, nonce)
+
+	return &CTRStream{
+		block:     block,
+		layout:    layout,
+		counter:   counter,
+		keystream: make([]byte, blockSize),
+		index:     -1,
+		limit:     limit,
+	}, nil
+}
+
+// XORKeyStream XORs each byte of src against the keystream at the current
+// offset and writes it to dst, which can be the same as src. Like the
+// other cipher.Stream implementations it panics if dst is smaller than
+// src. It also panics rather than wrap the counter around, which would
+// reuse the keystream.
+func (c *CTRStream) XORKeyStream(dst, src []byte) {
+	if len(dst) < len(src) {
+		panic("cryptopals: output smaller than input")
+	}
+	if c.limit > 0 && c.offset+int64(len(src)) > c.limit {
+		panic("cryptopals: counter would wrap around")
+	}
+
+	blockSize := int64(c.block.BlockSize())
+	for len(src) > 0 {
+		index, position := c.offset/blockSize, c.offset%blockSize
+		if index != c.index {
+			c.layout.putCounter(c.counter[c.layout.NonceSize:], uint64(index))
+			c.block.Encrypt(c.keystream, c.counter)
+			c.index = index
+		}
+
+		n := len(src)
+		if remaining := int(blockSize - position); n > remaining {
+			n = remaining
+		}
+		xorBytes(dst[:n], src[:n], c.keystream[position:])
+
+		dst, src = dst[n:], src[n:]
+		c.offset += int64(n)
+	}
+}
+
+// Seek sets the offset of the next byte to be XORed, relative to the start
+// of the stream or the current offset. The stream only ends when the
+// counter runs out, so io.SeekEnd isn't supported.
+func (c *CTRStream) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += c.offset
+	default:
+		return c.offset, fmt.Errorf("unsupported whence: %d", whence)
+	}
+
+	if offset < 0 {
+		return c.offset, fmt.Errorf("negative offset: %d", offset)
+	}
+	if c.limit > 0 && offset > c.limit {
+		return c.offset, fmt.Errorf("offset is beyond the end of the counter: %d > %d", offset, c.limit)
+	}
+	c.offset = offset
+
+	return c.offset, nil
+}
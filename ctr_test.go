@@ -0,0 +1,194 @@
+package cryptopals_test
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"encoding/binary"
+	"io"
+
+	. "github.com/dcarley/cryptopals"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CTRStream", func() {
+	var (
+		block cipher.Block
+		plain = []byte("I'm back and I'm ringin' the bell, a rockin' on the mike while the fly girls yell")
+	)
+
+	BeforeEach(func() {
+		var err error
+		block, err = aes.NewCipher([]byte("YELLOW SUBMARINE"))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should match crypto/cipher with a big-endian counter", func() {
+		nonce := []byte("0123456789ab")
+		iv := append(append([]byte{}, nonce...), 0, 0, 0, 0)
+
+		expected := make([]byte, len(plain))
+		cipher.NewCTR(block, iv).XORKeyStream(expected, plain)
+
+		stream, err := NewCTRStream(block, nonce, CTR96BE)
+		Expect(err).ToNot(HaveOccurred())
+		out := make([]byte, len(plain))
+		stream.XORKeyStream(out, plain)
+		Expect(out).To(Equal(expected))
+	})
+
+	It("should put a little-endian counter after the nonce", func() {
+		nonce := []byte("01234567")
+		stream, err := NewCTRStream(block, nonce, CTR64LE)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = stream.Seek(3*16, io.SeekStart)
+		Expect(err).ToNot(HaveOccurred())
+
+		out := make([]byte, 16)
+		stream.XORKeyStream(out, out)
+
+		counter := make([]byte, 16)
+		copy(counter, nonce)
+		binary.LittleEndian.PutUint64(counter[8:], 3)
+		expected := make([]byte, 16)
+		block.Encrypt(expected, counter)
+		Expect(out).To(Equal(expected))
+	})
+
+	It("should work with other block sizes", func() {
+		block, err := des.NewCipher([]byte("YELLOW S"))
+		Expect(err).ToNot(HaveOccurred())
+		layout := CTRLayout{NonceSize: 4, CounterSize: 4, Order: binary.BigEndian}
+
+		expected := make([]byte, len(plain))
+		cipher.NewCTR(block, []byte("nonc\x00\x00\x00\x00")).XORKeyStream(expected, plain)
+
+		stream, err := NewCTRStream(block, []byte("nonc"), layout)
+		Expect(err).ToNot(HaveOccurred())
+		out := make([]byte, len(plain))
+		stream.XORKeyStream(out, plain)
+		Expect(out).To(Equal(expected))
+	})
+
+	It("should keep its place between calls of any size", func() {
+		stream, err := NewCTRStream(block, make([]byte, 8), CTR64LE)
+		Expect(err).ToNot(HaveOccurred())
+		expected := make([]byte, len(plain))
+		stream.XORKeyStream(expected, plain)
+
+		stream, err = NewCTRStream(block, make([]byte, 8), CTR64LE)
+		Expect(err).ToNot(HaveOccurred())
+		out := append([]byte{}, plain...)
+		for i, size := 0, 1; i < len(out); i, size = i+size, size+2 {
+			end := i + size
+			if end > len(out) {
+				end = len(out)
+			}
+			stream.XORKeyStream(out[i:end], out[i:end])
+		}
+		Expect(out).To(Equal(expected))
+	})
+
+	DescribeTable("should seek to any offset",
+		func(seeks []int64, whence int, position int64) {
+			stream, err := NewCTRStream(block, make([]byte, 8), CTR64LE)
+			Expect(err).ToNot(HaveOccurred())
+			expected := make([]byte, len(plain))
+			stream.XORKeyStream(expected, plain)
+
+			// start from the middle of a block that's already been made
+			_, err = stream.Seek(5, io.SeekStart)
+			Expect(err).ToNot(HaveOccurred())
+			stream.XORKeyStream(make([]byte, 1), make([]byte, 1))
+
+			var offset int64
+			for _, seek := range seeks {
+				offset, err = stream.Seek(seek, whence)
+				Expect(err).ToNot(HaveOccurred())
+			}
+			Expect(offset).To(Equal(position))
+
+			out := make([]byte, len(plain)-int(position))
+			stream.XORKeyStream(out, plain[position:])
+			Expect(out).To(Equal(expected[position:]))
+		},
+		Entry("start", []int64{0}, io.SeekStart, int64(0)),
+		Entry("middle of a block", []int64{37}, io.SeekStart, int64(37)),
+		Entry("start of a block", []int64{48}, io.SeekStart, int64(48)),
+		Entry("relative to the current offset", []int64{10, -3}, io.SeekCurrent, int64(13)),
+	)
+
+	It("should refuse to wrap the counter around", func() {
+		layout := CTRLayout{NonceSize: 14, CounterSize: 2, Order: binary.BigEndian}
+		stream, err := NewCTRStream(block, make([]byte, 14), layout)
+		Expect(err).ToNot(HaveOccurred())
+
+		end := int64(1<<16) * 16
+		_, err = stream.Seek(end+1, io.SeekStart)
+		Expect(err).To(MatchError("offset is beyond the end of the counter: 1048577 > 1048576"))
+
+		_, err = stream.Seek(end-2, io.SeekStart)
+		Expect(err).ToNot(HaveOccurred())
+		stream.XORKeyStream(make([]byte, 2), make([]byte, 2))
+		Expect(func() {
+			stream.XORKeyStream(make([]byte, 1), make([]byte, 1))
+		}).To(Panic())
+	})
+
+	It("should panic if dst is smaller than src", func() {
+		stream, err := NewCTRStream(block, make([]byte, 8), CTR64LE)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(func() {
+			stream.XORKeyStream(make([]byte, 1), make([]byte, 2))
+		}).To(Panic())
+	})
+
+	DescribeTable("seek errors",
+		func(offset int64, whence int, message string) {
+			stream, err := NewCTRStream(block, make([]byte, 8), CTR64LE)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = stream.Seek(2, io.SeekStart)
+			Expect(err).ToNot(HaveOccurred())
+
+			position, err := stream.Seek(offset, whence)
+			Expect(err).To(MatchError(message))
+			Expect(position).To(BeEquivalentTo(2))
+		},
+		Entry("end of the stream", int64(0), io.SeekEnd, "unsupported whence: 2"),
+		Entry("negative offset", int64(-3), io.SeekCurrent, "negative offset: -1"),
+	)
+
+	DescribeTable("errors",
+		func(nonce []byte, layout CTRLayout, message string) {
+			stream, err := NewCTRStream(block, nonce, layout)
+			Expect(err).To(MatchError(message))
+			Expect(stream).To(BeNil())
+		},
+		Entry("wrong nonce size", make([]byte, 12), CTR64LE, "nonce must be 8 bytes: 12"),
+		Entry("unsupported counter size",
+			make([]byte, 13), CTRLayout{NonceSize: 13, CounterSize: 3, Order: binary.BigEndian},
+			"counter must be 2, 4 or 8 bytes: 3"),
+		Entry("layout doesn't fill a block",
+			make([]byte, 4), CTRLayout{NonceSize: 4, CounterSize: 8, Order: binary.BigEndian},
+			"nonce and counter must be same size as block: 4 + 8 != 16"),
+		Entry("missing byte order",
+			make([]byte, 8), CTRLayout{NonceSize: 8, CounterSize: 8},
+			"counter byte order must be set"),
+	)
+
+	It("should round trip with bytes that aren't a whole block", func() {
+		stream, err := NewCTRStream(block, bytes.Repeat([]byte{0xff}, 12), CTR96BE)
+		Expect(err).ToNot(HaveOccurred())
+		out := make([]byte, 5)
+		stream.XORKeyStream(out, []byte("hello"))
+
+		stream, err = NewCTRStream(block, bytes.Repeat([]byte{0xff}, 12), CTR96BE)
+		Expect(err).ToNot(HaveOccurred())
+		stream.XORKeyStream(out, out)
+		Expect(string(out)).To(Equal("hello"))
+	})
+})
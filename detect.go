@@ -0,0 +1,185 @@
+package cryptopals
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"unicode"
+)
+
+// Names of the encodings that DetectEncoding can recognise.
+const (
+	EncodingHex       = "hex"
+	EncodingBase64    = "base64"
+	EncodingBase64URL = "base64url"
+	EncodingBase32    = "base32"
+	EncodingRaw       = "raw"
+)
+
+// EncodingGuess is a possible encoding for some text and how confident we
+// are that it's correct, between 0 and 1.
+type EncodingGuess struct {
+	Name       string
+	Confidence float64
+}
+
+// encodingCandidate is an encoding that DetectEncoding will try.
+type encodingCandidate struct {
+	name string
+	// decode is called with whitespace already removed
+	decode func(text []byte) ([]byte, error)
+	// confidence is how likely it is to be this encoding if it decodes
+	// without error, before adjusting for size. Encodings with smaller
+	// alphabets get higher values because it's less likely that text would
+	// fit in them by chance. Encodings with the same alphabet are ordered
+	// by how common they are.
+	confidence float64
+	// distinct are characters that only appear in this encoding, which
+	// make it more likely when present
+	distinct []byte
+	// quantum is the number of characters that padded output is always a
+	// multiple of, which makes it less likely when the input isn't
+	quantum int
+}
+
+// withBase64Padding wraps a base64 decoder that requires padding so that
+// it also accepts input without any, because we don't know whether the
+// sender used it. Input that has some padding is decoded as it is, so
+// padding that stops early is still rejected.
+func withBase64Padding(decode func(text []byte) ([]byte, error)) func(text []byte) ([]byte, error) {
+	return func(text []byte) ([]byte, error) {
+		if remainder := len(text) % 4; remainder > 1 && bytes.IndexByte(text, '=') == -1 {
+			text = append(text[:len(text):len(text)], bytes.Repeat([]byte("="), 4-remainder)...)
+		}
+
+		return decode(text)
+	}
+}
+
+var encodingCandidates = []encodingCandidate{
+	{
+		name:       EncodingHex,
+		decode:     HexDecode,
+		confidence: 0.9,
+	},
+	{
+		name:       EncodingBase32,
+		decode:     StdBase32.Decode,
+		confidence: 0.8,
+	},
+	{
+		name:       EncodingBase64,
+		decode:     withBase64Padding(Base64Decode),
+		confidence: 0.7,
+		distinct:   []byte("+/"),
+		quantum:    4,
+	},
+	{
+		name:       EncodingBase64URL,
+		decode:     withBase64Padding(URLBase64.Decode),
+		confidence: 0.6,
+		distinct:   []byte("-_"),
+		quantum:    4,
+	},
+}
+
+// rawConfidence is how likely it is that text which can be decoded isn't
+// actually encoded. It's low, but not zero, because short words like
+// "beef" or "CAFE" are also valid hex or base64.
+const rawConfidence = 0.05
+
+// shortInput is the number of characters below which we're less confident,
+// because short input can fit into more alphabets by chance.
+const shortInput = 16
+
+// DetectEncoding returns the encodings that text could be in, ranked by how
+// confident we are. Confidences add up to 1. Line breaks are ignored, so
+// multi-line input such as a file is treated as one blob. EncodingRaw is
+// always included, for text that isn't encoded at all.
+func DetectEncoding(text []byte) []EncodingGuess {
+	guesses, _ := detectEncoding(text)
+
+	return guesses
+}
+
+// DecodeAny decodes text using the most likely encoding from
+// DetectEncoding, which it also returns.
+func DecodeAny(text []byte) ([]byte, EncodingGuess, error) {
+	guesses, decoded := detectEncoding(text)
+	best := guesses[0]
+
+	out, ok := decoded[best.Name]
+	if !ok {
+		return []byte{}, best, fmt.Errorf("no decoder for encoding: %s", best.Name)
+	}
+
+	return out, best, nil
+}
+
+// detectEncoding does the work for DetectEncoding and DecodeAny, also
+// returning the output for each encoding that decoded successfully.
+func detectEncoding(text []byte) ([]EncodingGuess, map[string][]byte) {
+	// encoded text can be split into lines, but other whitespace such as
+	// spaces between words means that it probably isn't encoded
+	stripped := make([]byte, 0, len(text))
+	for _, char := range bytes.TrimSpace(text) {
+		if char != '\n' && char != '\r' {
+			stripped = append(stripped, char)
+		}
+	}
+	encoded := len(stripped) > 0 && bytes.IndexFunc(stripped, unicode.IsSpace) == -1
+
+	decoded := map[string][]byte{
+		EncodingRaw: append([]byte{}, text...),
+	}
+	guesses := []EncodingGuess{}
+
+	if encoded {
+		for _, candidate := range encodingCandidates {
+			out, err := candidate.decode(stripped)
+			if err != nil {
+				continue
+			}
+
+			confidence := candidate.confidence
+			if len(candidate.distinct) > 0 && bytes.ContainsAny(stripped, string(candidate.distinct)) {
+				confidence = 1
+			}
+			if len(stripped) < shortInput {
+				confidence /= 2
+			}
+			if candidate.quantum > 0 && len(stripped)%candidate.quantum != 0 {
+				confidence /= 2
+			}
+
+			decoded[candidate.name] = out
+			guesses = append(guesses, EncodingGuess{
+				Name:       candidate.name,
+				Confidence: confidence,
+			})
+		}
+	}
+
+	// if nothing else fits then it must be raw
+	if len(guesses) == 0 {
+		guesses = append(guesses, EncodingGuess{Name: EncodingRaw, Confidence: 1})
+	} else {
+		guesses = append(guesses, EncodingGuess{Name: EncodingRaw, Confidence: rawConfidence})
+	}
+
+	// normalise so that the confidences add up to 1
+	var total float64
+	for _, guess := range guesses {
+		total += guess.Confidence
+	}
+	for i := range guesses {
+		guesses[i].Confidence /= total
+	}
+
+	// stable so that candidates with equal confidence keep their order
+	sort.SliceStable(guesses, func(i, j int) bool {
+		return guesses[i].Confidence > guesses[j].Confidence
+	})
+
+	return guesses, decoded
+}
@@ -0,0 +1,126 @@
+package cryptopals_test
+
+import (
+	"io/ioutil"
+
+	. "github.com/dcarley/cryptopals"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Detect", func() {
+	DescribeTable("DetectEncoding fixtures",
+		func(fixture, expected string) {
+			text, err := ioutil.ReadFile(fixture)
+			Expect(err).ToNot(HaveOccurred())
+
+			guesses := DetectEncoding(text)
+			Expect(guesses[0].Name).To(Equal(expected))
+		},
+		Entry("challenge 4", "fixtures/s1c4", EncodingHex),
+		Entry("challenge 6", "fixtures/s1c6", EncodingBase64),
+		Entry("challenge 7", "fixtures/s1c7", EncodingBase64),
+		Entry("challenge 8", "fixtures/s1c8", EncodingHex),
+		Entry("plain text", "fixtures/s1c6.plain", EncodingRaw),
+	)
+
+	DescribeTable("DetectEncoding",
+		func(text string, expected []string) {
+			var names []string
+			var total float64
+			for _, guess := range DetectEncoding([]byte(text)) {
+				names = append(names, guess.Name)
+				total += guess.Confidence
+			}
+
+			Expect(names).To(Equal(expected))
+			Expect(total).To(BeNumerically("~", 1))
+		},
+		Entry("hex",
+			"68656c6c6f20676f70686572",
+			[]string{EncodingHex, EncodingBase64, EncodingBase64URL, EncodingRaw},
+		),
+		Entry("base32",
+			"NBSWY3DPEBTW64DIMVZA====",
+			[]string{EncodingBase32, EncodingRaw},
+		),
+		Entry("base64",
+			"aGVsbG8gZ29waGVy",
+			[]string{EncodingBase64, EncodingBase64URL, EncodingRaw},
+		),
+		Entry("base64 with standard characters",
+			"+/+/aGVsbG8gZ29waGVy",
+			[]string{EncodingBase64, EncodingRaw},
+		),
+		Entry("base64 with URL safe characters",
+			"-_-_aGVsbG8gZ29waGVy",
+			[]string{EncodingBase64URL, EncodingRaw},
+		),
+		Entry("base64 without padding",
+			"aGVsbG8gZ29waGVyIQ",
+			[]string{EncodingBase64, EncodingBase64URL, EncodingRaw},
+		),
+		Entry("base64 with padding that stops early",
+			"aGVsbG8gZ29waGVyIQ=",
+			[]string{EncodingRaw},
+		),
+		Entry("raw",
+			"hello gopher",
+			[]string{EncodingRaw},
+		),
+		Entry("empty",
+			"",
+			[]string{EncodingRaw},
+		),
+	)
+
+	Describe("DecodeAny", func() {
+		It("should decode a base64 fixture", func() {
+			text, err := ioutil.ReadFile("fixtures/s1c6")
+			Expect(err).ToNot(HaveOccurred())
+
+			expected, err := Base64Decode(text)
+			Expect(err).ToNot(HaveOccurred())
+
+			decoded, guess, err := DecodeAny(text)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(guess.Name).To(Equal(EncodingBase64))
+			Expect(decoded).To(Equal(expected))
+		})
+
+		It("should decode hex split across lines", func() {
+			decoded, guess, err := DecodeAny([]byte("68656c6c6f20\n676f70686572\n"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(guess.Name).To(Equal(EncodingHex))
+			Expect(decoded).To(Equal([]byte("hello gopher")))
+		})
+
+		DescribeTable("should return malformed base64 padding as raw text",
+			func(text string) {
+				decoded, guess, err := DecodeAny([]byte(text))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(guess.Name).To(Equal(EncodingRaw))
+				Expect(decoded).To(Equal([]byte(text)))
+			},
+			Entry("one padding character after two", "aG="),
+			Entry("one padding character after a quantum", "aGVsbA="),
+			Entry("padding in the middle", "aG==aGVs"),
+		)
+
+		It("should decode base64 without padding", func() {
+			decoded, guess, err := DecodeAny([]byte("aGVsbG8gZ29waGVyIQ"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(guess.Name).To(Equal(EncodingBase64))
+			Expect(decoded).To(Equal([]byte("hello gopher!")))
+		})
+
+		It("should return raw text as is", func() {
+			decoded, guess, err := DecodeAny([]byte("hello gopher"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(guess.Name).To(Equal(EncodingRaw))
+			Expect(decoded).To(Equal([]byte("hello gopher")))
+		})
+	})
+})
@@ -0,0 +1,168 @@
+package cryptopals
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// LineScore is the most likely single byte key for a line of text.
+type LineScore struct {
+	// Line is the line number, starting from 1.
+	Line int
+	KeyScore
+}
+
+// DetectXOROptions control how DetectSingleByteXOR scores lines.
+type DetectXOROptions struct {
+	// Decode decodes each line. It defaults to HexDecode, and
+	// Base64Decode can also be used.
+	Decode func(text []byte) ([]byte, error)
+	// Scorer decides which plaintext is most likely. It defaults to
+	// ETAOINScorer. It must be safe for concurrent use.
+	Scorer Scorer
+	// Workers is how many lines to score at the same time. It defaults to
+	// GOMAXPROCS.
+	Workers int
+	// Limit is how many lines to return. Zero returns all of them.
+	Limit int
+	// MaxLineSize is the longest line that can be read, in bytes. It
+	// defaults to DefaultMaxLineSize.
+	MaxLineSize int
+}
+
+// DefaultMaxLineSize is the longest line that DetectSingleByteXOR reads
+// when DetectXOROptions.MaxLineSize isn't set. It's much bigger than
+// bufio.MaxScanTokenSize, so that long encoded lines can be read.
+const DefaultMaxLineSize = 1 << 20
+
+// lineJob is a decoded line that a worker finds a single byte key for.
+type lineJob struct {
+	line int
+	text []byte
+}
+
+// DetectSingleByteXOR finds which lines of r are most likely to have been
+// XORed against a single byte key, like challenge 4. Each line is decoded
+// and brute forced in a pool of workers, and the results are returned
+// highest score first, or by line number when the scores are the same.
+// Blank lines are skipped. It returns ctx.Err() if ctx is done before it
+// finishes.
+func DetectSingleByteXOR(ctx context.Context, r io.Reader, opts DetectXOROptions) ([]LineScore, error) {
+	decode := opts.Decode
+	if decode == nil {
+		decode = HexDecode
+	}
+
+	scorer := opts.Scorer
+	if scorer == nil {
+		scorer = ETAOINScorer
+	}
+
+	workers := opts.Workers
+	switch {
+	case workers < 0:
+		return []LineScore{}, fmt.Errorf("workers must not be negative: %d", workers)
+	case workers == 0:
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if opts.Limit < 0 {
+		return []LineScore{}, fmt.Errorf("limit must not be negative: %d", opts.Limit)
+	}
+
+	maxLineSize := opts.MaxLineSize
+	switch {
+	case maxLineSize < 0:
+		return []LineScore{}, fmt.Errorf("max line size must not be negative: %d", maxLineSize)
+	case maxLineSize == 0:
+		maxLineSize = DefaultMaxLineSize
+	}
+
+	var (
+		scores []LineScore
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		jobs   = make(chan lineJob)
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				// only returns an error for an empty key, which it won't be
+				score, _ := BruteForceSingleByteXORWithScorer(job.text, scorer)
+
+				mu.Lock()
+				scores = append(scores, LineScore{Line: job.line, KeyScore: score})
+				mu.Unlock()
+			}
+		}()
+	}
+
+	// read lines while the workers score them, and stop at the first error
+	err := func() error {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(nil, maxLineSize)
+		line := 1
+		for ; scanner.Scan(); line++ {
+			trimmed := bytes.TrimSpace(scanner.Bytes())
+			if len(trimmed) == 0 {
+				continue
+			}
+
+			decoded, err := decode(trimmed)
+			if err != nil {
+				return fmt.Errorf("line %d: %w", line, err)
+			}
+			// the scanner reuses its buffer, which decode could return
+			text := append([]byte{}, decoded...)
+
+			select {
+			case jobs <- lineJob{line: line, text: text}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			if errors.Is(err, bufio.ErrTooLong) {
+				return fmt.Errorf("line %d is longer than %d bytes", line, maxLineSize)
+			}
+			return err
+		}
+
+		return nil
+	}()
+	close(jobs)
+	wg.Wait()
+
+	if err == nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		return []LineScore{}, err
+	}
+
+	// workers finish in any order
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Score == scores[j].Score {
+			return scores[i].Line < scores[j].Line
+		}
+		return scores[i].Score > scores[j].Score
+	})
+
+	if opts.Limit > 0 && len(scores) > opts.Limit {
+		scores = scores[:opts.Limit]
+	}
+	if scores == nil {
+		scores = []LineScore{}
+	}
+
+	return scores, nil
+}
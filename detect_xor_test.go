@@ -0,0 +1,125 @@
+package cryptopals_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"strings"
+
+	. "github.com/dcarley/cryptopals"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DetectSingleByteXOR", func() {
+	var file *os.File
+
+	BeforeEach(func() {
+		var err error
+		file, err = os.Open("fixtures/s1c4")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(file.Close()).To(Succeed())
+	})
+
+	It("should solve challenge 4", func() {
+		scores, err := DetectSingleByteXOR(context.Background(), file, DetectXOROptions{Limit: 3})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(scores).To(HaveLen(3))
+		Expect(scores[0].Line).To(Equal(171))
+		Expect(scores[0].Key).To(Equal([]byte("5")))
+		Expect(scores[0].Text).To(Equal([]byte("Now that the party is jumping\n")))
+		Expect(scores[1].Score).To(BeNumerically("<=", scores[0].Score))
+		Expect(scores[2].Score).To(BeNumerically("<=", scores[1].Score))
+	})
+
+	It("should return the same results with any number of workers", func() {
+		expected, err := DetectSingleByteXOR(context.Background(), file, DetectXOROptions{Workers: 1})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(expected).To(HaveLen(327))
+
+		for _, workers := range []int{0, 4, 32} {
+			_, err := file.Seek(0, 0)
+			Expect(err).ToNot(HaveOccurred())
+
+			scores, err := DetectSingleByteXOR(context.Background(), file, DetectXOROptions{Workers: workers})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(scores).To(Equal(expected))
+		}
+	})
+
+	It("should decode base64 and skip blank lines", func() {
+		input := "\n" +
+			"eJs0yvVPLiIKzZQecbiNWDaGbQ2Fi2NU\n" +
+			"  \n" +
+			"GiYrM2o+Iis+aiw/JCEzaic/OSMpaj0iIz4vaiglMw==\n"
+
+		scores, err := DetectSingleByteXOR(context.Background(), strings.NewReader(input), DetectXOROptions{
+			Decode: Base64Decode,
+			Scorer: EnglishChiSquaredScorer,
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(scores).To(HaveLen(2))
+		Expect(scores[0].Line).To(Equal(4))
+		Expect(scores[0].Text).To(Equal([]byte("Play that funky music white boy")))
+		Expect(scores[1].Line).To(Equal(2))
+	})
+
+	It("should return an error with the line number", func() {
+		_, err := DetectSingleByteXOR(context.Background(), strings.NewReader("abcd\n\nxyzz\n"), DetectXOROptions{})
+		Expect(err).To(MatchError("line 3: invalid hex character: x at offset 0"))
+
+		var decodeErr *DecodeError
+		Expect(errors.As(err, &decodeErr)).To(BeTrue())
+	})
+
+	It("should stop when the context is cancelled", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := DetectSingleByteXOR(ctx, file, DetectXOROptions{})
+		Expect(err).To(Equal(context.Canceled))
+	})
+
+	It("should read lines longer than the bufio.Scanner default", func() {
+		text := bytes.Repeat([]byte("Cooking MC's like a pound of bacon "), 3000)
+		xor, err := RepeatingKeyXOR(text, []byte("X"))
+		Expect(err).ToNot(HaveOccurred())
+		line := HexEncode(xor)
+		Expect(len(line)).To(BeNumerically(">", bufio.MaxScanTokenSize))
+
+		scores, err := DetectSingleByteXOR(context.Background(), bytes.NewReader(line), DetectXOROptions{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(scores).To(HaveLen(1))
+		Expect(scores[0].Key).To(Equal([]byte("X")))
+		Expect(scores[0].Text).To(Equal(text))
+	})
+
+	It("should return an error for lines longer than MaxLineSize", func() {
+		input := strings.NewReader("1b37\n1b3737\n")
+		_, err := DetectSingleByteXOR(context.Background(), input, DetectXOROptions{MaxLineSize: 5})
+		Expect(err).To(MatchError("line 2 is longer than 5 bytes"))
+	})
+
+	It("should return no lines for empty input", func() {
+		scores, err := DetectSingleByteXOR(context.Background(), strings.NewReader(""), DetectXOROptions{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(scores).To(Equal([]LineScore{}))
+	})
+
+	It("should return an error for invalid options", func() {
+		_, err := DetectSingleByteXOR(context.Background(), file, DetectXOROptions{Workers: -1})
+		Expect(err).To(MatchError("workers must not be negative: -1"))
+
+		_, err = DetectSingleByteXOR(context.Background(), file, DetectXOROptions{Limit: -1})
+		Expect(err).To(MatchError("limit must not be negative: -1"))
+
+		_, err = DetectSingleByteXOR(context.Background(), file, DetectXOROptions{MaxLineSize: -1})
+		Expect(err).To(MatchError("max line size must not be negative: -1"))
+	})
+})
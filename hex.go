@@ -0,0 +1,110 @@
+package cryptopals
+
+import (
+	"io"
+)
+
+// hexBufferSize is how many characters the streaming hex encoder and decoder
+// will hold in memory at once. It must be an even number.
+const hexBufferSize = 1024
+
+// hexEncoder hex encodes everything written to it before passing it on to
+// an underlying writer.
+type hexEncoder struct {
+	w   io.Writer
+	buf [hexBufferSize]byte
+}
+
+// NewHexEncoder returns a writer that hex encodes everything written to it
+// and writes the result to w.
+func NewHexEncoder(w io.Writer) io.Writer {
+	return &hexEncoder{w: w}
+}
+
+func (e *hexEncoder) Write(p []byte) (int, error) {
+	var n int
+	for len(p) > 0 {
+		// each byte in becomes two characters out, so only encode as much
+		// as will fit in the buffer
+		chunk := len(p)
+		if chunk > len(e.buf)/2 {
+			chunk = len(e.buf) / 2
+		}
+
+		out := AppendHexEncode(e.buf[:0], p[:chunk])
+		written, err := e.w.Write(out)
+		n += written / 2
+		if err != nil {
+			return n, err
+		}
+
+		p = p[chunk:]
+	}
+
+	return n, nil
+}
+
+// hexDecoder decodes hex read from an underlying reader.
+type hexDecoder struct {
+	r      io.Reader
+	err    error
+	in     []byte // characters read but not yet decoded
+	offset int64  // position of in[0] within the whole input
+	buf    [hexBufferSize]byte
+}
+
+// NewHexDecoder returns a reader that decodes hex read from r. The input
+// may be split across reads at any point, including between the two
+// characters of a byte. Invalid characters are returned as a *DecodeError
+// with their offset from the start of the input, and ErrOddHexLength is
+// returned if the input ends part way through a byte.
+func NewHexDecoder(r io.Reader) io.Reader {
+	return &hexDecoder{r: r}
+}
+
+func (d *hexDecoder) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	// keep reading until we have at least one pair of characters, carrying
+	// over any single character left from the previous read
+	for len(d.in) < 2 && d.err == nil {
+		carried := copy(d.buf[:], d.in)
+		n, err := d.r.Read(d.buf[carried:])
+		d.in = d.buf[:carried+n]
+		d.err = err
+	}
+
+	if len(d.in) < 2 {
+		// a dangling character at the end means half a byte is missing
+		if d.err == io.EOF && len(d.in) == 1 {
+			d.err = ErrOddHexLength
+		}
+
+		return 0, d.err
+	}
+
+	// only decode whole pairs of characters and no more than will fit in p
+	size := len(d.in) / 2 * 2
+	if size > len(p)*2 {
+		size = len(p) * 2
+	}
+
+	out, err := AppendHexDecode(p[:0], d.in[:size])
+	if err != nil {
+		// make the offset relative to the whole input instead of this chunk
+		if decodeErr, ok := err.(*DecodeError); ok {
+			decodeErr.Offset += d.offset
+		}
+
+		d.in = nil
+		d.err = err
+		return len(out), err
+	}
+
+	d.in = d.in[size:]
+	d.offset += int64(size)
+
+	return len(out), nil
+}
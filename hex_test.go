@@ -0,0 +1,136 @@
+package cryptopals_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io/ioutil"
+	"testing/iotest"
+
+	. "github.com/dcarley/cryptopals"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Hex", func() {
+	// every possible byte value, so that all of the lookups are exercised
+	allBytes := make([]byte, 256)
+	for i := range allBytes {
+		allBytes[i] = byte(i)
+	}
+
+	Describe("AppendHexEncode", func() {
+		It("should append to an existing slice", func() {
+			out := AppendHexEncode([]byte("prefix:"), []byte("hello gopher"))
+			Expect(out).To(Equal([]byte("prefix:68656c6c6f20676f70686572")))
+		})
+
+		It("should not allocate when there is enough capacity", func() {
+			dst := make([]byte, 0, 64)
+			out := AppendHexEncode(dst, []byte("hello gopher"))
+			Expect(&out[0]).To(BeIdenticalTo(&dst[:1][0]))
+		})
+	})
+
+	Describe("AppendHexDecode", func() {
+		It("should append to an existing slice", func() {
+			out, err := AppendHexDecode([]byte("prefix:"), []byte("676f70686572"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(out).To(Equal([]byte("prefix:gopher")))
+		})
+
+		It("should keep the bytes decoded before an invalid character", func() {
+			out, err := AppendHexDecode([]byte{}, []byte("676fzz686572"))
+			Expect(err).To(MatchError("invalid hex character: z at offset 4"))
+			Expect(out).To(Equal([]byte("go")))
+		})
+
+		It("should return an error on odd input sizes", func() {
+			_, err := AppendHexDecode([]byte{}, []byte("abc"))
+			Expect(err).To(Equal(ErrOddHexLength))
+		})
+	})
+
+	Describe("NewHexEncoder", func() {
+		It("should encode all byte values", func() {
+			var out bytes.Buffer
+			n, err := NewHexEncoder(&out).Write(allBytes)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(n).To(Equal(len(allBytes)))
+			Expect(out.String()).To(Equal(hex.EncodeToString(allBytes)))
+		})
+
+		It("should encode input larger than the buffer", func() {
+			input := bytes.Repeat(allBytes, 20)
+
+			var out bytes.Buffer
+			n, err := NewHexEncoder(&out).Write(input)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(n).To(Equal(len(input)))
+			Expect(out.String()).To(Equal(hex.EncodeToString(input)))
+		})
+	})
+
+	Describe("NewHexDecoder", func() {
+		It("should decode all byte values", func() {
+			decoded, err := ioutil.ReadAll(NewHexDecoder(
+				bytes.NewReader([]byte(hex.EncodeToString(allBytes))),
+			))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(decoded).To(Equal(allBytes))
+		})
+
+		It("should decode input larger than the buffer", func() {
+			input := bytes.Repeat(allBytes, 20)
+
+			decoded, err := ioutil.ReadAll(NewHexDecoder(
+				bytes.NewReader([]byte(hex.EncodeToString(input))),
+			))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(decoded).To(Equal(input))
+		})
+
+		It("should decode characters of a byte split across reads", func() {
+			decoded, err := ioutil.ReadAll(NewHexDecoder(
+				iotest.OneByteReader(bytes.NewReader([]byte("676f70686572"))),
+			))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(decoded).To(Equal([]byte("gopher")))
+		})
+
+		It("should decode into a destination smaller than the input", func() {
+			decoded, err := ioutil.ReadAll(iotest.OneByteReader(NewHexDecoder(
+				bytes.NewReader([]byte("676f70686572")),
+			)))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(decoded).To(Equal([]byte("gopher")))
+		})
+
+		DescribeTable("errors",
+			func(input string, expected string, decoded []byte) {
+				out, err := ioutil.ReadAll(NewHexDecoder(
+					iotest.HalfReader(bytes.NewReader([]byte(input))),
+				))
+				Expect(err).To(MatchError(expected))
+				Expect(out).To(Equal(decoded))
+			},
+			Entry("odd number of characters",
+				"676f706", "input must be an even size", []byte("gop"),
+			),
+			Entry("invalid first character",
+				"676f70g8", "invalid hex character: g at offset 6", []byte("gop"),
+			),
+			Entry("invalid second character",
+				"676f706z", "invalid hex character: z at offset 7", []byte("gop"),
+			),
+		)
+
+		It("should report offsets from the start of input larger than the buffer", func() {
+			input := append([]byte(hex.EncodeToString(bytes.Repeat(allBytes, 5))), 'x', 'x')
+
+			_, err := ioutil.ReadAll(NewHexDecoder(bytes.NewReader(input)))
+			Expect(err).To(MatchError("invalid hex character: x at offset 2560"))
+		})
+	})
+})
@@ -0,0 +1,264 @@
+package cryptopals
+
+import (
+	"fmt"
+	"sort"
+)
+
+// KeySizeEstimator guesses the most likely key sizes for some XORed text,
+// sorted in order of probability. GuessXORKeySizeWithOptions,
+// GuessXORKeySizeIC, GuessXORKeySizeKasiski and GuessXORKeySizeCombined
+// are all estimators.
+type KeySizeEstimator func(text []byte, opts KeySizeOptions) ([]int, error)
+
+const (
+	// icTolerance is how close, as a proportion, the index of coincidence
+	// of a factor of a key size must be for the factor to be preferred.
+	icTolerance = 0.25
+	// kasiskiLength is the length of the repeated sequences that
+	// GuessXORKeySizeKasiski looks for.
+	kasiskiLength = 3
+)
+
+// keySizeScore is the score of a key size, where higher is more likely.
+type keySizeScore struct {
+	size  int
+	score float64
+}
+
+// validateKeySizeOptions returns an error if opts can't be used.
+func validateKeySizeOptions(opts KeySizeOptions) error {
+	switch {
+	case opts.MinSize < 1:
+		return fmt.Errorf("minimum key size must be positive: %d", opts.MinSize)
+	case opts.MaxSize < opts.MinSize:
+		return fmt.Errorf("maximum key size must not be less than minimum: %d < %d", opts.MaxSize, opts.MinSize)
+	case opts.BlockPairs < 0:
+		return fmt.Errorf("block pairs must not be negative: %d", opts.BlockPairs)
+	case opts.Guesses < 1:
+		return fmt.Errorf("guesses must be positive: %d", opts.Guesses)
+	}
+
+	return nil
+}
+
+// rankKeySizes returns up to guesses key sizes, highest score first.
+// When multiples of the real key size score nearly as well as it, sizes can
+// be preceded by their smallest factor that scores within tolerance, as a
+// proportion, of them. Equal scores are won by the smaller size.
+func rankKeySizes(scores []keySizeScore, guesses int, tolerance float64) []int {
+	bySize := make(map[int]float64, len(scores))
+	for _, score := range scores {
+		bySize[score.size] = score.score
+	}
+
+	sort.SliceStable(scores, func(i, j int) bool {
+		if scores[i].score == scores[j].score {
+			return scores[i].size < scores[j].size
+		}
+		return scores[i].score > scores[j].score
+	})
+
+	var (
+		sizes []int
+		used  = map[int]bool{}
+	)
+	add := func(size int) {
+		if !used[size] && len(sizes) < guesses {
+			sizes = append(sizes, size)
+			used[size] = true
+		}
+	}
+
+	for _, score := range scores {
+		for factor := 1; factor < score.size; factor++ {
+			factorScore, ok := bySize[factor]
+			if ok && score.size%factor == 0 && factorScore >= score.score-tolerance*abs(score.score) {
+				add(factor)
+				break
+			}
+		}
+		add(score.size)
+	}
+
+	return sizes
+}
+
+// abs returns the absolute value of x.
+func abs(x float64) float64 {
+	if x < 0 {
+		return -x
+	}
+
+	return x
+}
+
+// GuessXORKeySizeIC guesses the most likely key size for some XORed text
+// using the index of coincidence, which is the probability that two bytes
+// picked at random are the same:
+// https://en.wikipedia.org/wiki/Index_of_coincidence
+//
+// Each byte of a key XORs its column of the text to a different alphabet,
+// but doesn't change how often each character occurs. So when the text is
+// split into columns of the right size, they have the same high index of
+// coincidence as plaintext, and columns of the wrong size mix alphabets
+// together which makes the index lower. opts.BlockPairs is ignored.
+func GuessXORKeySizeIC(text []byte, opts KeySizeOptions) ([]int, error) {
+	if err := validateKeySizeOptions(opts); err != nil {
+		return []int{}, err
+	}
+
+	var scores []keySizeScore
+	for keySize := opts.MinSize; keySize <= opts.MaxSize; keySize++ {
+		// every column needs at least two bytes to compare
+		if len(text)/keySize < 2 {
+			break
+		}
+
+		var total float64
+		for _, column := range TransposeBlocks(text, keySize) {
+			total += IndexOfCoincidence(column)
+		}
+
+		scores = append(scores, keySizeScore{
+			size:  keySize,
+			score: total / float64(keySize),
+		})
+	}
+
+	if len(scores) == 0 {
+		return []int{}, fmt.Errorf("text is too short for key size %d: %d bytes", opts.MinSize, len(text))
+	}
+
+	return rankKeySizes(scores, opts.Guesses, icTolerance), nil
+}
+
+// IndexOfCoincidence returns the probability that two different bytes
+// picked at random from text are the same.
+func IndexOfCoincidence(text []byte) float64 {
+	if len(text) < 2 {
+		return 0
+	}
+
+	var counts [256]int
+	for _, char := range text {
+		counts[char]++
+	}
+
+	var pairs int
+	for _, count := range counts {
+		pairs += count * (count - 1)
+	}
+
+	return float64(pairs) / float64(len(text)*(len(text)-1))
+}
+
+// GuessXORKeySizeKasiski guesses the most likely key size for some XORed
+// text using the Kasiski examination:
+// https://en.wikipedia.org/wiki/Kasiski_examination
+//
+// When the same plaintext is XORed against the same part of the key it
+// repeats in the text, so the distances between repeated sequences are
+// usually multiples of the key size. Every size divides 1/size of distances
+// by chance, so sizes are scored by how many more distances they divide
+// than that, which is highest for the key size rather than its factors or
+// multiples. opts.BlockPairs is ignored.
+func GuessXORKeySizeKasiski(text []byte, opts KeySizeOptions) ([]int, error) {
+	if err := validateKeySizeOptions(opts); err != nil {
+		return []int{}, err
+	}
+
+	// distance from each sequence to the last time that it appeared
+	var distances []int
+	seen := map[string]int{}
+	for i := 0; i+kasiskiLength <= len(text); i++ {
+		sequence := string(text[i : i+kasiskiLength])
+		if last, ok := seen[sequence]; ok {
+			distances = append(distances, i-last)
+		}
+		seen[sequence] = i
+	}
+
+	if len(distances) == 0 {
+		return []int{}, fmt.Errorf("text has no repeated sequences of %d bytes", kasiskiLength)
+	}
+
+	var scores []keySizeScore
+	for keySize := opts.MinSize; keySize <= opts.MaxSize; keySize++ {
+		// sizes bigger than every distance would score nearly 0, which is
+		// more than the real size can when few distances are multiples of
+		// it, so they need at least two blocks like the other estimators
+		if len(text)/keySize < 2 {
+			break
+		}
+
+		var divides int
+		for _, distance := range distances {
+			if distance%keySize == 0 {
+				divides++
+			}
+		}
+
+		scores = append(scores, keySizeScore{
+			size:  keySize,
+			score: float64(divides) - float64(len(distances))/float64(keySize),
+		})
+	}
+
+	if len(scores) == 0 {
+		return []int{}, fmt.Errorf("text is too short for key size %d: %d bytes", opts.MinSize, len(text))
+	}
+
+	return rankKeySizes(scores, opts.Guesses, 0), nil
+}
+
+// GuessXORKeySizeCombined guesses the most likely key size for some XORed
+// text by combining the rankings of GuessXORKeySizeWithOptions,
+// GuessXORKeySizeIC and GuessXORKeySizeKasiski using reciprocal rank
+// fusion, where each size scores 1/(1+rank) from every estimator:
+// https://plg.uwaterloo.ca/~gvcormac/cormacksigir09-rrf.pdf
+//
+// Estimators which return an error, such as when there are no repeated
+// sequences for Kasiski, are left out unless they all fail.
+func GuessXORKeySizeCombined(text []byte, opts KeySizeOptions) ([]int, error) {
+	if err := validateKeySizeOptions(opts); err != nil {
+		return []int{}, err
+	}
+
+	// rank every size, not just the guesses
+	all := opts
+	all.Guesses = opts.MaxSize + 1 - opts.MinSize
+
+	var (
+		fused   = map[int]float64{}
+		lastErr error
+		ranked  bool
+	)
+	for _, estimator := range []KeySizeEstimator{
+		GuessXORKeySizeWithOptions,
+		GuessXORKeySizeIC,
+		GuessXORKeySizeKasiski,
+	} {
+		sizes, err := estimator(text, all)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		ranked = true
+		for rank, size := range sizes {
+			fused[size] += 1 / float64(1+rank)
+		}
+	}
+
+	if !ranked {
+		return []int{}, lastErr
+	}
+
+	scores := make([]keySizeScore, 0, len(fused))
+	for size, score := range fused {
+		scores = append(scores, keySizeScore{size: size, score: score})
+	}
+
+	return rankKeySizes(scores, opts.Guesses, 0), nil
+}
@@ -0,0 +1,135 @@
+package cryptopals_test
+
+import (
+	"io/ioutil"
+
+	. "github.com/dcarley/cryptopals"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Key size", func() {
+	var plain []byte
+
+	BeforeEach(func() {
+		var err error
+		plain, err = ioutil.ReadFile("fixtures/s1c6.plain")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	estimators := []TableEntry{
+		Entry("index of coincidence", KeySizeEstimator(GuessXORKeySizeIC)),
+		Entry("Kasiski", KeySizeEstimator(GuessXORKeySizeKasiski)),
+		Entry("combined", KeySizeEstimator(GuessXORKeySizeCombined)),
+	}
+
+	DescribeTable("should guess key sizes, and not their multiples, for short text",
+		func(estimator KeySizeEstimator) {
+			for _, key := range []string{"ICE", "secret", "ICEICEBABY", "Terminator X: Bring the noise"} {
+				xor, err := RepeatingKeyXOR(plain[:300], []byte(key))
+				Expect(err).ToNot(HaveOccurred())
+
+				sizes, err := estimator(xor, DefaultKeySizeOptions)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(sizes).To(HaveLen(4))
+				Expect(sizes[0]).To(Equal(len(key)), key)
+			}
+		},
+		estimators...,
+	)
+
+	DescribeTable("should solve challenge 6 with BruteForceMultiByteXORWithOptions",
+		func(estimator KeySizeEstimator) {
+			b64, err := ioutil.ReadFile("fixtures/s1c6")
+			Expect(err).ToNot(HaveOccurred())
+			xor, err := Base64Decode(b64)
+			Expect(err).ToNot(HaveOccurred())
+
+			score, err := BruteForceMultiByteXORWithOptions(xor, MultiByteXOROptions{
+				KeySizeOptions:   DefaultKeySizeOptions,
+				KeySizeEstimator: estimator,
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(score.Key).To(Equal([]byte("Terminator X: Bring the noise")))
+			Expect(score.Text).To(Equal(plain))
+		},
+		estimators...,
+	)
+
+	DescribeTable("should only guess key sizes that fit in the text twice",
+		func(estimator KeySizeEstimator, text, key string) {
+			xor, err := RepeatingKeyXOR([]byte(text), []byte(key))
+			Expect(err).ToNot(HaveOccurred())
+
+			sizes, err := estimator(xor, DefaultKeySizeOptions)
+			Expect(err).ToNot(HaveOccurred())
+			for _, size := range sizes {
+				Expect(size).To(BeNumerically("<=", len(text)/2))
+			}
+
+			score, err := BruteForceMultiByteXORWithOptions(xor, MultiByteXOROptions{
+				KeySizeOptions:   DefaultKeySizeOptions,
+				KeySizeEstimator: estimator,
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(score.Key)).To(BeNumerically("<=", len(text)/2))
+		},
+		Entry("Kasiski", KeySizeEstimator(GuessXORKeySizeKasiski), "the rain in spa", "KEY"),
+		Entry("combined", KeySizeEstimator(GuessXORKeySizeCombined), "od luck, I like my rhymes atrociou", "ICE"),
+	)
+
+	It("should still guess the key size for short text with Kasiski", func() {
+		xor, err := RepeatingKeyXOR([]byte("the rain in spa"), []byte("KEY"))
+		Expect(err).ToNot(HaveOccurred())
+
+		sizes, err := GuessXORKeySizeKasiski(xor, DefaultKeySizeOptions)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sizes[0]).To(Equal(3))
+	})
+
+	DescribeTable("errors",
+		func(estimator KeySizeEstimator, text []byte, opts KeySizeOptions, message string) {
+			sizes, err := estimator(text, opts)
+			Expect(err).To(MatchError(message))
+			Expect(sizes).To(Equal([]int{}))
+		},
+		Entry("index of coincidence with short text", KeySizeEstimator(GuessXORKeySizeIC),
+			[]byte("abc"), DefaultKeySizeOptions, "text is too short for key size 2: 3 bytes"),
+		Entry("Kasiski without repeats", KeySizeEstimator(GuessXORKeySizeKasiski),
+			[]byte("abcdefghijklmnopqrstuvwxyz"), DefaultKeySizeOptions, "text has no repeated sequences of 3 bytes"),
+		Entry("combined when all fail", KeySizeEstimator(GuessXORKeySizeCombined),
+			[]byte("abc"), DefaultKeySizeOptions, "text has no repeated sequences of 3 bytes"),
+		Entry("Kasiski with short text", KeySizeEstimator(GuessXORKeySizeKasiski),
+			[]byte("abcabc"), KeySizeOptions{MinSize: 4, MaxSize: 40, Guesses: 4}, "text is too short for key size 4: 6 bytes"),
+		Entry("invalid options", KeySizeEstimator(GuessXORKeySizeKasiski),
+			[]byte("abcabc"), KeySizeOptions{MinSize: 1, MaxSize: 2}, "guesses must be positive: 0"),
+	)
+
+	Describe("GuessXORKeySizeCombined", func() {
+		It("should still guess when Kasiski fails", func() {
+			xor, err := RepeatingKeyXOR(plain[:120], []byte("Terminator X: Bring the noise"))
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = GuessXORKeySizeKasiski(xor, DefaultKeySizeOptions)
+			Expect(err).To(HaveOccurred())
+
+			sizes, err := GuessXORKeySizeCombined(xor, DefaultKeySizeOptions)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sizes[0]).To(Equal(29))
+		})
+	})
+
+	Describe("IndexOfCoincidence", func() {
+		It("should return the probability of two bytes being the same", func() {
+			Expect(IndexOfCoincidence([]byte("aaaa"))).To(Equal(1.0))
+			Expect(IndexOfCoincidence([]byte("abcd"))).To(Equal(0.0))
+			Expect(IndexOfCoincidence([]byte("aabb"))).To(BeNumerically("~", 4.0/12))
+		})
+
+		It("should return 0 for text that is too short", func() {
+			Expect(IndexOfCoincidence([]byte("a"))).To(Equal(0.0))
+		})
+	})
+})
@@ -0,0 +1,162 @@
+package cryptopals
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// RecoverXORKey recovers the repeating key that some text has been XORed
+// against when part of the plaintext, such as a file header, is known to be
+// at offset.
+//
+// XORing the known plaintext against the text reveals the part of the key
+// that it covers. A key size is consistent with that if the revealed bytes
+// repeat every key size bytes. When the known plaintext is long enough to
+// show the key repeating, the smallest size that it repeats at is used.
+// Otherwise the consistent sizes are tried in the order of
+// opts.KeySizeEstimator, with the key bytes which aren't revealed found by
+// RankSingleByteXOR, and the key that makes the most likely plaintext is
+// returned. opts.Workers is ignored.
+func RecoverXORKey(text, known []byte, offset int, opts MultiByteXOROptions) (KeyScore, error) {
+	if len(known) == 0 {
+		return KeyScore{}, errors.New("known plaintext must not be empty")
+	}
+	if offset < 0 {
+		return KeyScore{}, fmt.Errorf("offset must not be negative: %d", offset)
+	}
+	if offset+len(known) > len(text) {
+		return KeyScore{}, fmt.Errorf("known plaintext doesn't fit at offset %d: %d + %d > %d", offset, offset, len(known), len(text))
+	}
+	if err := validateKeySizeOptions(opts.KeySizeOptions); err != nil {
+		return KeyScore{}, err
+	}
+
+	scorer := opts.Scorer
+	if scorer == nil {
+		scorer = ETAOINScorer
+	}
+
+	estimator := opts.KeySizeEstimator
+	if estimator == nil {
+		estimator = GuessXORKeySizeWithOptions
+	}
+
+	keystream, err := FixedKeyXOR(text[offset:offset+len(known)], known)
+	if err != nil {
+		return KeyScore{}, err
+	}
+
+	// a key size is confirmed when the keystream repeats at least twice,
+	// which only happens by chance for 1 in 65536 wrong sizes, and then its
+	// multiples are too
+	var consistent []int
+	for keySize := opts.MinSize; keySize <= opts.MaxSize; keySize++ {
+		if !repeatsEvery(keystream, keySize) {
+			continue
+		}
+		if len(keystream)-keySize >= 2 {
+			consistent = []int{keySize}
+			break
+		}
+		consistent = append(consistent, keySize)
+	}
+
+	if len(consistent) == 0 {
+		return KeyScore{}, fmt.Errorf("no key size between %d and %d is consistent with the known plaintext", opts.MinSize, opts.MaxSize)
+	}
+
+	keySizes := consistent
+	if len(consistent) > 1 {
+		keySizes = rankKeySizesWith(text, consistent, estimator, opts.KeySizeOptions)
+	}
+
+	var highestScore KeyScore
+	for _, keySize := range keySizes {
+		key, err := completeXORKey(text, keystream, offset, keySize, scorer)
+		if err != nil {
+			return KeyScore{}, err
+		}
+
+		out, err := RepeatingKeyXOR(text, key)
+		if err != nil {
+			return KeyScore{}, err
+		}
+
+		candidate := KeyScore{Score: scorer.Score(out), Key: key, Text: out}
+		if better(candidate, highestScore) {
+			highestScore = candidate
+		}
+	}
+
+	return highestScore, nil
+}
+
+// repeatsEvery reports whether every byte of keystream is the same as the
+// byte size bytes after it.
+func repeatsEvery(keystream []byte, size int) bool {
+	for i := 0; i+size < len(keystream); i++ {
+		if keystream[i] != keystream[i+size] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// rankKeySizesWith returns up to opts.Guesses of keySizes, in the order of
+// estimator. When estimator fails, such as when the text is too short,
+// they're returned smallest first.
+func rankKeySizesWith(text []byte, keySizes []int, estimator KeySizeEstimator, opts KeySizeOptions) []int {
+	all := opts
+	all.Guesses = opts.MaxSize + 1 - opts.MinSize
+
+	ranked := map[int]int{}
+	if guesses, err := estimator(text, all); err == nil {
+		for i, size := range guesses {
+			ranked[size] = i + 1
+		}
+	}
+
+	sorted := append([]int{}, keySizes...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		rankI, okI := ranked[sorted[i]]
+		rankJ, okJ := ranked[sorted[j]]
+		if okI != okJ {
+			return okI
+		}
+		return rankI < rankJ
+	})
+
+	if len(sorted) > opts.Guesses {
+		sorted = sorted[:opts.Guesses]
+	}
+
+	return sorted
+}
+
+// completeXORKey makes a key of keySize from the keystream revealed at
+// offset, finding the bytes that it doesn't cover with RankSingleByteXOR.
+func completeXORKey(text, keystream []byte, offset, keySize int, scorer Scorer) ([]byte, error) {
+	key := make([]byte, keySize)
+	revealed := make([]bool, keySize)
+	for i, char := range keystream {
+		key[(offset+i)%keySize] = char
+		revealed[(offset+i)%keySize] = true
+	}
+
+	columns := TransposeBlocks(text, keySize)
+	for i := range key {
+		if revealed[i] {
+			continue
+		}
+
+		ranking, err := RankSingleByteXOR(columns[i], scorer, 1)
+		if err != nil {
+			return []byte{}, err
+		}
+		key[i] = ranking.Best().Key[0]
+	}
+
+	return key, nil
+}
@@ -0,0 +1,75 @@
+package cryptopals_test
+
+import (
+	"io/ioutil"
+
+	. "github.com/dcarley/cryptopals"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RecoverXORKey", func() {
+	opts := MultiByteXOROptions{
+		KeySizeOptions: DefaultKeySizeOptions,
+		Scorer:         EnglishChiSquaredScorer,
+	}
+
+	It("should recover a key that is shorter than the known plaintext", func() {
+		plain := []byte("HTTP/1.1 200 OK\r\nContent-Type: text/plain\r\n\r\nhello")
+		key := []byte{0x8f, 0x00, 0x3c, 0xfe, 0x71, 0x12, 0xa9}
+		xor, err := RepeatingKeyXOR(plain, key)
+		Expect(err).ToNot(HaveOccurred())
+
+		score, err := RecoverXORKey(xor, []byte("HTTP/1.1 "), 0, opts)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(score.Key).To(Equal(key))
+		Expect(score.Text).To(Equal(plain))
+	})
+
+	It("should find the rest of a key that is longer than the known plaintext", func() {
+		plain, err := ioutil.ReadFile("fixtures/s1c6.plain")
+		Expect(err).ToNot(HaveOccurred())
+		key := []byte("Terminator X: Bring the noise")
+		xor, err := RepeatingKeyXOR(plain, key)
+		Expect(err).ToNot(HaveOccurred())
+
+		score, err := RecoverXORKey(xor, []byte("the bell"), 25, opts)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(score.Key).To(Equal(key))
+		Expect(score.Text).To(Equal(plain))
+	})
+
+	It("should use known plaintext that wraps around the key", func() {
+		plain := []byte(`{"name": "Vanilla Ice", "hits": ["Ice Ice Baby", "Play That Funky Music"]}`)
+		key := []byte("\x01\xffSECRET")
+		xor, err := RepeatingKeyXOR(plain, key)
+		Expect(err).ToNot(HaveOccurred())
+
+		score, err := RecoverXORKey(xor, []byte(`Ice", "hits": [`), 18, MultiByteXOROptions{
+			KeySizeOptions: DefaultKeySizeOptions,
+			Scorer:         JSONScorer,
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(score.Key).To(Equal(key))
+	})
+
+	DescribeTable("errors",
+		func(text, known []byte, offset int, opts MultiByteXOROptions, message string) {
+			_, err := RecoverXORKey(text, known, offset, opts)
+			Expect(err).To(MatchError(message))
+		},
+		Entry("empty known plaintext", []byte("abcd"), []byte{}, 0, opts,
+			"known plaintext must not be empty"),
+		Entry("known plaintext past the end", []byte("abcd"), []byte("cde"), 2, opts,
+			"known plaintext doesn't fit at offset 2: 2 + 3 > 4"),
+		Entry("negative offset", []byte("abcd"), []byte("a"), -1, opts,
+			"offset must not be negative: -1"),
+		Entry("invalid options", []byte("abcd"), []byte("a"), 0, MultiByteXOROptions{},
+			"minimum key size must be positive: 0"),
+		Entry("no consistent key size", []byte("abcdef"), []byte("\x00\x00\x00\x00\x00\x00"), 0,
+			MultiByteXOROptions{KeySizeOptions: KeySizeOptions{MinSize: 2, MaxSize: 4, Guesses: 1}},
+			"no key size between 2 and 4 is consistent with the known plaintext"),
+	)
+})
@@ -0,0 +1,259 @@
+package cryptopals
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// languageModelOrders are the sizes of n-gram that a LanguageModel counts.
+var languageModelOrders = []int{1, 2, 4}
+
+// languageModelMagic identifies the file format written by
+// LanguageModel.WriteTo, followed by a version number.
+const (
+	languageModelMagic   = "CPLM"
+	languageModelVersion = 1
+)
+
+// LanguageModel is the number of times that each unigram, bigram and
+// quadgram appears in a training corpus. Letters are lowercase and
+// whitespace is a single space, the same as NgramScorer with KeepSpaces.
+type LanguageModel struct {
+	// Counts are indexed by the size of n-gram: 1, 2 or 4.
+	Counts map[int]map[string]uint64
+	// window holds the last few characters added, so that n-grams which
+	// span calls to Write are counted.
+	window []byte
+}
+
+// NewLanguageModel returns an empty model, ready to be trained by writing
+// to it.
+func NewLanguageModel() *LanguageModel {
+	counts := make(map[int]map[string]uint64, len(languageModelOrders))
+	for _, n := range languageModelOrders {
+		counts[n] = map[string]uint64{}
+	}
+
+	return &LanguageModel{Counts: counts}
+}
+
+// TrainLanguageModel returns a model trained on everything read from r,
+// such as a file of plaintext.
+func TrainLanguageModel(r io.Reader) (*LanguageModel, error) {
+	model := NewLanguageModel()
+	if _, err := io.Copy(model, r); err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
+// Write trains the model on some more text. It never returns an error.
+func (m *LanguageModel) Write(text []byte) (int, error) {
+	maxOrder := languageModelOrders[len(languageModelOrders)-1]
+
+	for _, char := range foldNgram(text) {
+		// collapse whitespace to a single space
+		if isWhitespace(char) {
+			if len(m.window) > 0 && m.window[len(m.window)-1] == ' ' {
+				continue
+			}
+			char = ' '
+		}
+
+		m.window = append(m.window, char)
+		if len(m.window) > maxOrder {
+			m.window = m.window[1:]
+		}
+
+		// count every n-gram that ends with this character
+		for _, n := range languageModelOrders {
+			if len(m.window) >= n {
+				m.Counts[n][string(m.window[len(m.window)-n:])]++
+			}
+		}
+	}
+
+	return len(text), nil
+}
+
+// NgramScorer returns a scorer for n-grams of size n, which must be 1, 2 or
+// 4. Larger sizes back off to unigrams for n-grams that weren't seen in
+// training.
+func (m *LanguageModel) NgramScorer(n int) (*NgramScorer, error) {
+	counts, ok := m.Counts[n]
+	if !ok {
+		return nil, fmt.Errorf("language model doesn't have n-grams of size: %d", n)
+	}
+
+	scorer := m.ngramScorer(counts, n)
+	if n > 1 {
+		scorer.Backoff = m.ngramScorer(m.Counts[1], 1)
+	}
+
+	return scorer, nil
+}
+
+// ngramScorer converts one size of counts to a scorer.
+func (m *LanguageModel) ngramScorer(counts map[string]uint64, n int) *NgramScorer {
+	var total float64
+	floatCounts := make(map[string]float64, len(counts))
+	for ngram, count := range counts {
+		floatCounts[ngram] = float64(count)
+		total += float64(count)
+	}
+
+	// avoid dividing by zero for an untrained model
+	if total == 0 {
+		total = 1
+	}
+
+	scorer := NewNgramScorer(n, floatCounts, total)
+	scorer.KeepSpaces = true
+
+	return scorer
+}
+
+// Scorer returns a quadgram scorer, which is the most accurate for longer
+// texts.
+func (m *LanguageModel) Scorer() Scorer {
+	scorer, _ := m.NgramScorer(4)
+
+	return scorer
+}
+
+// WriteTo writes the model in a compact binary format that can be read by
+// ReadLanguageModel. The format is:
+//
+//	"CPLM" version
+//	for each size of n-gram: size count (ngram count)...
+//
+// where all numbers are unsigned varints, n-grams are sorted and each is
+// size bytes long.
+func (m *LanguageModel) WriteTo(w io.Writer) (int64, error) {
+	bw := bufio.NewWriter(w)
+	var written int64
+
+	writeUvarint := func(v uint64) error {
+		var buf [binary.MaxVarintLen64]byte
+		n, err := bw.Write(buf[:binary.PutUvarint(buf[:], v)])
+		written += int64(n)
+		return err
+	}
+
+	n, err := bw.WriteString(languageModelMagic)
+	written += int64(n)
+	if err != nil {
+		return written, err
+	}
+	if err := writeUvarint(languageModelVersion); err != nil {
+		return written, err
+	}
+
+	for _, size := range languageModelOrders {
+		counts := m.Counts[size]
+
+		ngrams := make([]string, 0, len(counts))
+		for ngram := range counts {
+			ngrams = append(ngrams, ngram)
+		}
+		sort.Strings(ngrams)
+
+		if err := writeUvarint(uint64(size)); err != nil {
+			return written, err
+		}
+		if err := writeUvarint(uint64(len(ngrams))); err != nil {
+			return written, err
+		}
+
+		for _, ngram := range ngrams {
+			n, err := bw.WriteString(ngram)
+			written += int64(n)
+			if err != nil {
+				return written, err
+			}
+			if err := writeUvarint(counts[ngram]); err != nil {
+				return written, err
+			}
+		}
+	}
+
+	return written, bw.Flush()
+}
+
+// ErrInvalidLanguageModel is returned when a model file is corrupt or
+// truncated.
+var ErrInvalidLanguageModel = errors.New("invalid language model")
+
+// ReadLanguageModel reads a model that was written by WriteTo.
+func ReadLanguageModel(r io.Reader) (*LanguageModel, error) {
+	br := bufio.NewReader(r)
+
+	readUvarint := func() (uint64, error) {
+		v, err := binary.ReadUvarint(br)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %s", ErrInvalidLanguageModel, err)
+		}
+		return v, nil
+	}
+
+	magic := make([]byte, len(languageModelMagic))
+	if _, err := io.ReadFull(br, magic); err != nil || string(magic) != languageModelMagic {
+		return nil, fmt.Errorf("%w: not a language model file", ErrInvalidLanguageModel)
+	}
+
+	version, err := readUvarint()
+	if err != nil {
+		return nil, err
+	}
+	if version != languageModelVersion {
+		return nil, fmt.Errorf("%w: unsupported version: %d", ErrInvalidLanguageModel, version)
+	}
+
+	model := NewLanguageModel()
+	for _, expectedSize := range languageModelOrders {
+		size, err := readUvarint()
+		if err != nil {
+			return nil, err
+		}
+		if int(size) != expectedSize {
+			return nil, fmt.Errorf("%w: unexpected n-gram size: %d", ErrInvalidLanguageModel, size)
+		}
+
+		count, err := readUvarint()
+		if err != nil {
+			return nil, err
+		}
+
+		ngram := make([]byte, size)
+		for i := uint64(0); i < count; i++ {
+			if _, err := io.ReadFull(br, ngram); err != nil {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidLanguageModel, err)
+			}
+
+			v, err := readUvarint()
+			if err != nil {
+				return nil, err
+			}
+			model.Counts[expectedSize][string(ngram)] = v
+		}
+	}
+
+	return model, nil
+}
+
+// LoadLanguageModel reads a model from a file that was written by WriteTo.
+func LoadLanguageModel(path string) (*LanguageModel, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ReadLanguageModel(file)
+}
@@ -0,0 +1,157 @@
+package cryptopals_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing/iotest"
+
+	. "github.com/dcarley/cryptopals"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LanguageModel", func() {
+	var model *LanguageModel
+
+	BeforeEach(func() {
+		file, err := os.Open("fixtures/s1c6.plain")
+		Expect(err).ToNot(HaveOccurred())
+		defer file.Close()
+
+		model, err = TrainLanguageModel(file)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	Describe("TrainLanguageModel", func() {
+		It("should count n-grams with lowercase letters and single spaces", func() {
+			model, err := TrainLanguageModel(strings.NewReader("Hello \r\n hello"))
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(model.Counts[1]).To(Equal(map[string]uint64{
+				"h": 2, "e": 2, "l": 4, "o": 2, " ": 1,
+			}))
+			Expect(model.Counts[2]).To(Equal(map[string]uint64{
+				"he": 2, "el": 2, "ll": 2, "lo": 2, "o ": 1, " h": 1,
+			}))
+			Expect(model.Counts[4]).To(Equal(map[string]uint64{
+				"hell": 2, "ello": 2, "llo ": 1, "lo h": 1, "o he": 1, " hel": 1,
+			}))
+		})
+
+		It("should count n-grams that span reads", func() {
+			split, err := TrainLanguageModel(iotest.OneByteReader(strings.NewReader("Hello \r\n hello")))
+			Expect(err).ToNot(HaveOccurred())
+
+			whole, err := TrainLanguageModel(strings.NewReader("Hello \r\n hello"))
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(split.Counts).To(Equal(whole.Counts))
+		})
+	})
+
+	Describe("WriteTo and ReadLanguageModel", func() {
+		It("should round trip", func() {
+			var buf bytes.Buffer
+			n, err := model.WriteTo(&buf)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(n).To(BeEquivalentTo(buf.Len()))
+			Expect(buf.String()).To(HavePrefix("CPLM\x01"))
+
+			loaded, err := ReadLanguageModel(&buf)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(loaded.Counts).To(Equal(model.Counts))
+		})
+
+		It("should be deterministic", func() {
+			var one, two bytes.Buffer
+			_, err := model.WriteTo(&one)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = model.WriteTo(&two)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(one.Bytes()).To(Equal(two.Bytes()))
+		})
+
+		It("should load from a file", func() {
+			dir, err := ioutil.TempDir("", "cryptopals")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.RemoveAll(dir)
+
+			path := filepath.Join(dir, "english.model")
+			file, err := os.Create(path)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = model.WriteTo(file)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(file.Close()).To(Succeed())
+
+			loaded, err := LoadLanguageModel(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(loaded.Counts).To(Equal(model.Counts))
+		})
+
+		It("should return an error for other files", func() {
+			_, err := ReadLanguageModel(strings.NewReader("hello gopher"))
+			Expect(err).To(MatchError("invalid language model: not a language model file"))
+			Expect(errors.Is(err, ErrInvalidLanguageModel)).To(BeTrue())
+		})
+
+		It("should return an error for unsupported versions", func() {
+			_, err := ReadLanguageModel(strings.NewReader("CPLM\x02"))
+			Expect(err).To(MatchError("invalid language model: unsupported version: 2"))
+		})
+
+		It("should return an error for truncated files", func() {
+			var buf bytes.Buffer
+			_, err := model.WriteTo(&buf)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = ReadLanguageModel(bytes.NewReader(buf.Bytes()[:buf.Len()/2]))
+			Expect(errors.Is(err, ErrInvalidLanguageModel)).To(BeTrue())
+		})
+	})
+
+	Describe("NgramScorer", func() {
+		It("should return an error for sizes that weren't counted", func() {
+			_, err := model.NgramScorer(3)
+			Expect(err).To(MatchError("language model doesn't have n-grams of size: 3"))
+		})
+
+		It("should score English higher than not English", func() {
+			for _, n := range []int{1, 2, 4} {
+				scorer, err := model.NgramScorer(n)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(scorer.Score([]byte("Now that the party is jumping"))).To(
+					BeNumerically(">", scorer.Score([]byte("qui1Chux(euZae9Ua3pooquqqqqqq"))),
+				)
+			}
+		})
+	})
+
+	Describe("Scorer", func() {
+		It("should solve challenge 3", func() {
+			xor, err := HexDecode([]byte("1b37373331363f78151b7f2b783431333d78397828372d363c78373e783a393b3736"))
+			Expect(err).ToNot(HaveOccurred())
+
+			score, err := BruteForceSingleByteXORWithScorer(xor, model.Scorer())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(score.Text).To(Equal([]byte("Cooking MC's like a pound of bacon")))
+		})
+
+		It("should solve challenge 6", func() {
+			b64, err := ioutil.ReadFile("fixtures/s1c6")
+			Expect(err).ToNot(HaveOccurred())
+			xor, err := Base64Decode(b64)
+			Expect(err).ToNot(HaveOccurred())
+
+			score, err := BruteForceMultiByteXORWithScorer(xor, model.Scorer())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(score.Key).To(Equal([]byte("Terminator X: Bring the noise")))
+		})
+	})
+})
@@ -0,0 +1,263 @@
+package cryptopals
+
+import (
+	"crypto/cipher"
+	"fmt"
+)
+
+// The block cipher modes of operation, which work with any cipher.Block
+// such as AES, DES or 3DES:
+// https://en.wikipedia.org/wiki/Block_cipher_mode_of_operation
+//
+// ECB, CBC and PCBC implement cipher.BlockMode and only work on whole
+// blocks, so text must be padded first. CFB, OFB and CTR implement
+// cipher.Stream and work on any length of text. Like the crypto/cipher
+// implementations, they keep their place between calls and they panic if
+// dst is smaller than src, or for BlockMode if src isn't whole blocks,
+// because the interfaces don't return errors.
+
+// checkIV returns an error unless iv is the same size as a block.
+func checkIV(block cipher.Block, iv []byte) error {
+	if len(iv) != block.BlockSize() {
+		return fmt.Errorf("iv must be same size as block: %d != %d", len(iv), block.BlockSize())
+	}
+
+	return nil
+}
+
+// checkCryptBlocks panics like crypto/cipher if CryptBlocks is called
+// with the wrong sizes.
+func checkCryptBlocks(blockSize int, dst, src []byte) {
+	if len(src)%blockSize != 0 {
+		panic("cryptopals: input not full blocks")
+	}
+	if len(dst) < len(src) {
+		panic("cryptopals: output smaller than input")
+	}
+}
+
+// xorBytes sets dst to a XOR b, for the length of a.
+func xorBytes(dst, a, b []byte) {
+	for i := range a {
+		dst[i] = a[i] ^ b[i]
+	}
+}
+
+// ecb encrypts or decrypts each block on its own.
+type ecb struct {
+	block   cipher.Block
+	encrypt bool
+}
+
+// NewECBEncrypter returns a cipher.BlockMode which encrypts in ECB mode.
+func NewECBEncrypter(block cipher.Block) cipher.BlockMode {
+	return &ecb{block: block, encrypt: true}
+}
+
+// NewECBDecrypter returns a cipher.BlockMode which decrypts in ECB mode.
+func NewECBDecrypter(block cipher.Block) cipher.BlockMode {
+	return &ecb{block: block}
+}
+
+func (e *ecb) BlockSize() int {
+	return e.block.BlockSize()
+}
+
+func (e *ecb) CryptBlocks(dst, src []byte) {
+	blockSize := e.block.BlockSize()
+	checkCryptBlocks(blockSize, dst, src)
+
+	for i := 0; i < len(src); i += blockSize {
+		if e.encrypt {
+			e.block.Encrypt(dst[i:i+blockSize], src[i:i+blockSize])
+		} else {
+			e.block.Decrypt(dst[i:i+blockSize], src[i:i+blockSize])
+		}
+	}
+}
+
+// cbc chains each block to the ciphertext of the one before it. PCBC also
+// chains it to the plaintext.
+type cbc struct {
+	block    cipher.Block
+	encrypt  bool
+	plain    bool // PCBC
+	previous []byte
+	buf      []byte
+}
+
+func newCBC(block cipher.Block, iv []byte, encrypt, plain bool) (cipher.BlockMode, error) {
+	if err := checkIV(block, iv); err != nil {
+		return nil, err
+	}
+
+	return &cbc{
+		block:    block,
+		encrypt:  encrypt,
+		plain:    plain,
+		previous: append([]byte{}, iv...),
+		buf:      make([]byte, block.BlockSize()),
+	}, nil
+}
+
+// NewCBCEncrypter returns a cipher.BlockMode which encrypts in CBC mode.
+func NewCBCEncrypter(block cipher.Block, iv []byte) (cipher.BlockMode, error) {
+	return newCBC(block, iv, true, false)
+}
+
+// NewCBCDecrypter returns a cipher.BlockMode which decrypts in CBC mode.
+func NewCBCDecrypter(block cipher.Block, iv []byte) (cipher.BlockMode, error) {
+	return newCBC(block, iv, false, false)
+}
+
+// NewPCBCEncrypter returns a cipher.BlockMode which encrypts in PCBC mode,
+// where each block is XORed against both the plaintext and ciphertext of
+// the block before it.
+func NewPCBCEncrypter(block cipher.Block, iv []byte) (cipher.BlockMode, error) {
+	return newCBC(block, iv, true, true)
+}
+
+// NewPCBCDecrypter returns a cipher.BlockMode which decrypts in PCBC mode.
+func NewPCBCDecrypter(block cipher.Block, iv []byte) (cipher.BlockMode, error) {
+	return newCBC(block, iv, false, true)
+}
+
+func (c *cbc) BlockSize() int {
+	return c.block.BlockSize()
+}
+
+func (c *cbc) CryptBlocks(dst, src []byte) {
+	blockSize := c.block.BlockSize()
+	checkCryptBlocks(blockSize, dst, src)
+
+	// src and dst can be the same, so keep a copy of each input block for
+	// chaining before it's overwritten
+	for i := 0; i < len(src); i += blockSize {
+		in, out := src[i:i+blockSize], dst[i:i+blockSize]
+		copy(c.buf, in)
+
+		if c.encrypt {
+			xorBytes(out, in, c.previous)
+			c.block.Encrypt(out, out)
+			c.chain(c.buf, out)
+		} else {
+			c.block.Decrypt(out, in)
+			xorBytes(out, out, c.previous)
+			c.chain(out, c.buf)
+		}
+	}
+}
+
+// chain sets the value that the next block is XORed against.
+func (c *cbc) chain(plain, ciphertext []byte) {
+	if c.plain {
+		xorBytes(c.previous, plain, ciphertext)
+		return
+	}
+	copy(c.previous, ciphertext)
+}
+
+// feedbackMode is how a feedback stream makes its next block of keystream.
+type feedbackMode int
+
+const (
+	cfbMode feedbackMode = iota // from the last block of ciphertext
+	ofbMode                     // from the last block of keystream
+	ctrMode                     // from a counter
+)
+
+// feedback turns a block cipher into a stream, by encrypting a register to
+// make the keystream a block at a time.
+type feedback struct {
+	block     cipher.Block
+	mode      feedbackMode
+	decrypt   bool // CFB needs to know which side is ciphertext
+	register  []byte
+	keystream []byte
+	used      int // bytes of keystream used
+}
+
+func newFeedback(block cipher.Block, iv []byte, mode feedbackMode, decrypt bool) (cipher.Stream, error) {
+	if err := checkIV(block, iv); err != nil {
+		return nil, err
+	}
+
+	blockSize := block.BlockSize()
+	return &feedback{
+		block:     block,
+		mode:      mode,
+		decrypt:   decrypt,
+		register:  append([]byte{}, iv...),
+		keystream: make([]byte, blockSize),
+		used:      blockSize,
+	}, nil
+}
+
+// NewCFBEncrypter returns a cipher.Stream which encrypts in CFB mode, with
+// a segment size of one block, the same as cipher.NewCFBEncrypter.
+func NewCFBEncrypter(block cipher.Block, iv []byte) (cipher.Stream, error) {
+	return newFeedback(block, iv, cfbMode, false)
+}
+
+// NewCFBDecrypter returns a cipher.Stream which decrypts in CFB mode.
+func NewCFBDecrypter(block cipher.Block, iv []byte) (cipher.Stream, error) {
+	return newFeedback(block, iv, cfbMode, true)
+}
+
+// NewOFB returns a cipher.Stream which encrypts or decrypts in OFB mode.
+func NewOFB(block cipher.Block, iv []byte) (cipher.Stream, error) {
+	return newFeedback(block, iv, ofbMode, false)
+}
+
+// NewCTR returns a cipher.Stream which encrypts or decrypts in CTR mode,
+// where the whole IV is a big-endian counter, the same as cipher.NewCTR.
+func NewCTR(block cipher.Block, iv []byte) (cipher.Stream, error) {
+	return newFeedback(block, iv, ctrMode, false)
+}
+
+func (f *feedback) XORKeyStream(dst, src []byte) {
+	if len(dst) < len(src) {
+		panic("cryptopals: output smaller than input")
+	}
+
+	blockSize := f.block.BlockSize()
+	for i, in := range src {
+		// make more keystream from the register when it's all used
+		if f.used == blockSize {
+			f.block.Encrypt(f.keystream, f.register)
+			f.used = 0
+		}
+
+		dst[i] = in ^ f.keystream[f.used]
+
+		// CFB needs a whole block of ciphertext before it can feed back,
+		// so collect it in the register, which has already been used
+		if f.mode == cfbMode {
+			if f.decrypt {
+				f.register[f.used] = in
+			} else {
+				f.register[f.used] = dst[i]
+			}
+		}
+
+		f.used++
+		if f.used == blockSize {
+			switch f.mode {
+			case ofbMode:
+				copy(f.register, f.keystream)
+			case ctrMode:
+				incrementCounter(f.register)
+			}
+		}
+	}
+}
+
+// incrementCounter adds 1 to a big-endian counter, wrapping around to 0.
+func incrementCounter(counter []byte) {
+	for i := len(counter) - 1; i >= 0; i-- {
+		counter[i]++
+		if counter[i] != 0 {
+			return
+		}
+	}
+}
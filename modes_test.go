@@ -0,0 +1,273 @@
+package cryptopals_test
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+
+	. "github.com/dcarley/cryptopals"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Modes", func() {
+	newBlock := func(newCipher func([]byte) (cipher.Block, error), keySize int) cipher.Block {
+		key := bytes.Repeat([]byte("YELLOW SUBMARINE"), 2)[:keySize]
+		block, err := newCipher(key)
+		Expect(err).ToNot(HaveOccurred())
+
+		return block
+	}
+
+	ciphers := []TableEntry{
+		Entry("AES-128", aes.NewCipher, 16),
+		Entry("AES-192", aes.NewCipher, 24),
+		Entry("AES-256", aes.NewCipher, 32),
+		Entry("DES", des.NewCipher, 8),
+		Entry("3DES", des.NewTripleDESCipher, 24),
+	}
+
+	// three and a bit blocks for any of the ciphers, so that streams
+	// have to carry a partial block between calls
+	plain := []byte("I'm back and I'm ringin' the bell, a rockin' on the mike")
+
+	// cryptBlocks runs a BlockMode one block at a time, to check that it
+	// keeps its place between calls
+	cryptBlocks := func(mode cipher.BlockMode, text []byte) []byte {
+		out := make([]byte, len(text))
+		for i := 0; i < len(text); i += mode.BlockSize() {
+			mode.CryptBlocks(out[i:i+mode.BlockSize()], text[i:i+mode.BlockSize()])
+		}
+
+		return out
+	}
+
+	// xorKeyStream runs a Stream in place, in uneven chunks, to check that
+	// it keeps its place between calls
+	xorKeyStream := func(stream cipher.Stream, text []byte) []byte {
+		out := append([]byte{}, text...)
+		for i := 0; i < len(out); i += 5 {
+			end := i + 5
+			if end > len(out) {
+				end = len(out)
+			}
+			stream.XORKeyStream(out[i:end], out[i:end])
+		}
+
+		return out
+	}
+
+	Describe("ECB", func() {
+		DescribeTable("should encrypt each block on its own",
+			func(newCipher func([]byte) (cipher.Block, error), keySize int) {
+				block := newBlock(newCipher, keySize)
+				blockSize := block.BlockSize()
+				text, err := PKCS7Padding(plain, blockSize)
+				Expect(err).ToNot(HaveOccurred())
+
+				expected := make([]byte, len(text))
+				for i := 0; i < len(text); i += blockSize {
+					block.Encrypt(expected[i:i+blockSize], text[i:i+blockSize])
+				}
+
+				out := cryptBlocks(NewECBEncrypter(block), text)
+				Expect(out).To(Equal(expected))
+				Expect(DetectECBBlockSize(append(out, out...), blockSize)).To(BeTrue())
+
+				NewECBDecrypter(block).CryptBlocks(out, out)
+				Expect(out).To(Equal(text))
+			},
+			ciphers...,
+		)
+	})
+
+	Describe("CBC", func() {
+		DescribeTable("should match crypto/cipher",
+			func(newCipher func([]byte) (cipher.Block, error), keySize int) {
+				block := newBlock(newCipher, keySize)
+				text, err := PKCS7Padding(plain, block.BlockSize())
+				Expect(err).ToNot(HaveOccurred())
+				iv := bytes.Repeat([]byte{0x01}, block.BlockSize())
+
+				expected := make([]byte, len(text))
+				cipher.NewCBCEncrypter(block, iv).CryptBlocks(expected, text)
+
+				encrypter, err := NewCBCEncrypter(block, iv)
+				Expect(err).ToNot(HaveOccurred())
+				out := cryptBlocks(encrypter, text)
+				Expect(out).To(Equal(expected))
+
+				decrypter, err := NewCBCDecrypter(block, iv)
+				Expect(err).ToNot(HaveOccurred())
+				decrypter.CryptBlocks(out, out)
+				Expect(out).To(Equal(text))
+			},
+			ciphers...,
+		)
+	})
+
+	Describe("PCBC", func() {
+		DescribeTable("should round trip",
+			func(newCipher func([]byte) (cipher.Block, error), keySize int) {
+				block := newBlock(newCipher, keySize)
+				text, err := PKCS7Padding(plain, block.BlockSize())
+				Expect(err).ToNot(HaveOccurred())
+				iv := bytes.Repeat([]byte{0x01}, block.BlockSize())
+
+				encrypter, err := NewPCBCEncrypter(block, iv)
+				Expect(err).ToNot(HaveOccurred())
+				out := cryptBlocks(encrypter, text)
+
+				cbc := make([]byte, len(text))
+				cipher.NewCBCEncrypter(block, iv).CryptBlocks(cbc, text)
+				Expect(out[:block.BlockSize()]).To(Equal(cbc[:block.BlockSize()]))
+				Expect(out).ToNot(Equal(cbc))
+
+				decrypter, err := NewPCBCDecrypter(block, iv)
+				Expect(err).ToNot(HaveOccurred())
+				decrypter.CryptBlocks(out, out)
+				Expect(out).To(Equal(text))
+			},
+			ciphers...,
+		)
+
+		It("should propagate an error in the ciphertext to every later block", func() {
+			block := newBlock(aes.NewCipher, 16)
+			text := bytes.Repeat([]byte("YELLOW SUBMARINE"), 4)
+			iv := make([]byte, 16)
+
+			encrypter, err := NewPCBCEncrypter(block, iv)
+			Expect(err).ToNot(HaveOccurred())
+			out := make([]byte, len(text))
+			encrypter.CryptBlocks(out, text)
+			out[0] ^= 0x01
+
+			decrypter, err := NewPCBCDecrypter(block, iv)
+			Expect(err).ToNot(HaveOccurred())
+			decrypter.CryptBlocks(out, out)
+			for i := 0; i < len(text); i += 16 {
+				Expect(out[i : i+16]).ToNot(Equal(text[i : i+16]))
+			}
+		})
+	})
+
+	Describe("streams", func() {
+		DescribeTable("CFB should match crypto/cipher",
+			func(newCipher func([]byte) (cipher.Block, error), keySize int) {
+				block := newBlock(newCipher, keySize)
+				iv := bytes.Repeat([]byte{0x01}, block.BlockSize())
+
+				expected := make([]byte, len(plain))
+				cipher.NewCFBEncrypter(block, iv).XORKeyStream(expected, plain)
+
+				encrypter, err := NewCFBEncrypter(block, iv)
+				Expect(err).ToNot(HaveOccurred())
+				out := xorKeyStream(encrypter, plain)
+				Expect(out).To(Equal(expected))
+
+				decrypter, err := NewCFBDecrypter(block, iv)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(xorKeyStream(decrypter, out)).To(Equal(plain))
+			},
+			ciphers...,
+		)
+
+		DescribeTable("OFB should match crypto/cipher",
+			func(newCipher func([]byte) (cipher.Block, error), keySize int) {
+				block := newBlock(newCipher, keySize)
+				iv := bytes.Repeat([]byte{0x01}, block.BlockSize())
+
+				expected := make([]byte, len(plain))
+				cipher.NewOFB(block, iv).XORKeyStream(expected, plain)
+
+				stream, err := NewOFB(block, iv)
+				Expect(err).ToNot(HaveOccurred())
+				out := xorKeyStream(stream, plain)
+				Expect(out).To(Equal(expected))
+
+				stream, err = NewOFB(block, iv)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(xorKeyStream(stream, out)).To(Equal(plain))
+			},
+			ciphers...,
+		)
+
+		DescribeTable("CTR should match crypto/cipher",
+			func(newCipher func([]byte) (cipher.Block, error), keySize int) {
+				block := newBlock(newCipher, keySize)
+				// carries across a byte of the counter after the first block
+				iv := bytes.Repeat([]byte{0xff}, block.BlockSize())
+				iv[0] = 0x01
+
+				expected := make([]byte, len(plain))
+				cipher.NewCTR(block, iv).XORKeyStream(expected, plain)
+
+				stream, err := NewCTR(block, iv)
+				Expect(err).ToNot(HaveOccurred())
+				out := xorKeyStream(stream, plain)
+				Expect(out).To(Equal(expected))
+
+				stream, err = NewCTR(block, iv)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(xorKeyStream(stream, out)).To(Equal(plain))
+			},
+			ciphers...,
+		)
+
+		It("should not modify the IV", func() {
+			block := newBlock(aes.NewCipher, 16)
+			iv := make([]byte, 16)
+
+			stream, err := NewCTR(block, iv)
+			Expect(err).ToNot(HaveOccurred())
+			stream.XORKeyStream(make([]byte, 64), make([]byte, 64))
+			Expect(iv).To(Equal(make([]byte, 16)))
+		})
+
+		It("should panic if dst is smaller than src", func() {
+			stream, err := NewOFB(newBlock(aes.NewCipher, 16), make([]byte, 16))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(func() {
+				stream.XORKeyStream(make([]byte, 1), make([]byte, 2))
+			}).To(Panic())
+		})
+	})
+
+	It("should panic if CryptBlocks isn't given whole blocks", func() {
+		mode, err := NewCBCEncrypter(newBlock(des.NewCipher, 8), make([]byte, 8))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(func() {
+			mode.CryptBlocks(make([]byte, 12), make([]byte, 12))
+		}).To(Panic())
+		Expect(func() {
+			mode.CryptBlocks(make([]byte, 8), make([]byte, 16))
+		}).To(Panic())
+	})
+
+	DescribeTable("IV errors",
+		func(newMode func(cipher.Block, []byte) error) {
+			err := newMode(newBlock(des.NewCipher, 8), make([]byte, 16))
+			Expect(err).To(MatchError("iv must be same size as block: 16 != 8"))
+		},
+		Entry("CBC", func(b cipher.Block, iv []byte) error { _, err := NewCBCEncrypter(b, iv); return err }),
+		Entry("PCBC", func(b cipher.Block, iv []byte) error { _, err := NewPCBCDecrypter(b, iv); return err }),
+		Entry("CFB", func(b cipher.Block, iv []byte) error { _, err := NewCFBEncrypter(b, iv); return err }),
+		Entry("OFB", func(b cipher.Block, iv []byte) error { _, err := NewOFB(b, iv); return err }),
+		Entry("CTR", func(b cipher.Block, iv []byte) error { _, err := NewCTR(b, iv); return err }),
+	)
+
+	Describe("DetectECBBlockSize", func() {
+		DescribeTable("detection",
+			func(text string, blockSize int, expected bool) {
+				Expect(DetectECBBlockSize([]byte(text), blockSize)).To(Equal(expected))
+			},
+			Entry("repeated 8 byte blocks", "SUBMARINYELLOW  SUBMARIN", 8, true),
+			Entry("no repeated 8 byte blocks", "YELLOW SUBMARINE", 8, false),
+			Entry("repeat split by a partial block", "SUBMARINSUBMARI", 8, false),
+			Entry("invalid block size", "AAAA", 0, false),
+		)
+	})
+})
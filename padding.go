@@ -0,0 +1,239 @@
+package cryptopals
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"io"
+)
+
+// PaddingError is returned when padding can't be removed because it isn't
+// valid. It has no fields, because saying what was wrong with the padding
+// would make a padding oracle even easier to use:
+// https://en.wikipedia.org/wiki/Padding_oracle_attack
+type PaddingError struct{}
+
+func (PaddingError) Error() string {
+	return "invalid padding"
+}
+
+// ErrInvalidPadding is the PaddingError returned by every Padding, so that
+// it can be checked for with errors.Is.
+var ErrInvalidPadding error = PaddingError{}
+
+// Padding is a scheme for filling the last block of text before it's
+// encrypted with a block cipher, and removing it again afterwards.
+// https://en.wikipedia.org/wiki/Padding_(cryptography)
+type Padding interface {
+	// Pad returns a copy of text with padding added, so that it's a
+	// multiple of blockSize.
+	Pad(text []byte, blockSize int) ([]byte, error)
+	// Unpad returns text without padding, or ErrInvalidPadding if the
+	// padding isn't valid. The text isn't copied.
+	Unpad(text []byte, blockSize int) ([]byte, error)
+}
+
+var (
+	// PKCS7 pads with bytes that are each the length of the padding. A
+	// whole block is added if the text already fills its last block.
+	PKCS7 Padding = lengthPadding{fill: fillLength, check: checkLength}
+	// ANSIX923 pads with zeros, followed by the length of the padding.
+	ANSIX923 Padding = lengthPadding{fill: fillZeros, check: checkZeros}
+	// ISO10126 pads with random bytes, followed by the length of the
+	// padding. Only the length is checked when it's removed.
+	ISO10126 Padding = lengthPadding{fill: fillRandom}
+	// ISO7816 pads with a single 0x80 byte, followed by zeros. It's also
+	// known as ISO/IEC 7816-4 or bit padding, and works with any size of
+	// block.
+	ISO7816 Padding = iso7816Padding{}
+	// ZeroPadding pads with zeros, but only if the text doesn't already
+	// fill its last block. It can't be told apart from text that ends in
+	// zeros, so those are removed as well.
+	ZeroPadding Padding = zeroPadding{}
+)
+
+// paddings are the padding schemes that can be chosen by name in a
+// pipeline spec.
+var paddings = map[string]Padding{
+	"pkcs7":    PKCS7,
+	"x923":     ANSIX923,
+	"iso10126": ISO10126,
+	"iso7816":  ISO7816,
+	"zero":     ZeroPadding,
+}
+
+// checkBlockSize returns an error unless blockSize is between 1 and max,
+// or has no maximum if max is 0.
+func checkBlockSize(blockSize, max int) error {
+	if blockSize < 1 {
+		return fmt.Errorf("block size must be positive: %d", blockSize)
+	}
+	if max > 0 && blockSize > max {
+		return fmt.Errorf("block size must not be more than %d: %d", max, blockSize)
+	}
+
+	return nil
+}
+
+// checkPadded returns ErrInvalidPadding unless text is one or more whole
+// blocks.
+func checkPadded(text []byte, blockSize int) error {
+	if len(text) == 0 || len(text)%blockSize != 0 {
+		return ErrInvalidPadding
+	}
+
+	return nil
+}
+
+// lengthPadding is padding where the last byte is the length of the
+// padding, which means that blocks can't be bigger than 255 bytes. The
+// bytes before it are made by fill and, if they can be, checked by check.
+type lengthPadding struct {
+	fill  func(pad []byte) error
+	check func(pad []byte) bool
+}
+
+func (l lengthPadding) Pad(text []byte, blockSize int) ([]byte, error) {
+	if err := checkBlockSize(blockSize, 255); err != nil {
+		return []byte{}, err
+	}
+
+	padLength := blockSize - len(text)%blockSize
+	out := make([]byte, len(text)+padLength)
+	copy(out, text)
+
+	pad := out[len(text):]
+	if err := l.fill(pad); err != nil {
+		return []byte{}, err
+	}
+	pad[padLength-1] = byte(padLength)
+
+	return out, nil
+}
+
+func (l lengthPadding) Unpad(text []byte, blockSize int) ([]byte, error) {
+	if err := checkBlockSize(blockSize, 255); err != nil {
+		return []byte{}, err
+	}
+	if err := checkPadded(text, blockSize); err != nil {
+		return []byte{}, err
+	}
+
+	// last byte is the amount of padding, which is never 0
+	padLength := int(text[len(text)-1])
+	if padLength == 0 || padLength > blockSize {
+		return []byte{}, ErrInvalidPadding
+	}
+
+	pad := text[len(text)-padLength:]
+	if l.check != nil && !l.check(pad) {
+		return []byte{}, ErrInvalidPadding
+	}
+
+	return text[:len(text)-padLength], nil
+}
+
+// fillLength sets every byte of the padding to its length.
+func fillLength(pad []byte) error {
+	for i := range pad {
+		pad[i] = byte(len(pad))
+	}
+
+	return nil
+}
+
+// checkLength reports whether every byte of the padding is its length.
+func checkLength(pad []byte) bool {
+	for _, char := range pad {
+		if int(char) != len(pad) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// fillZeros leaves the padding as zeros, which it already is.
+func fillZeros(pad []byte) error {
+	return nil
+}
+
+// checkZeros reports whether every byte of the padding, other than the
+// length, is zero.
+func checkZeros(pad []byte) bool {
+	for _, char := range pad[:len(pad)-1] {
+		if char != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// fillRandom fills the padding with random bytes.
+func fillRandom(pad []byte) error {
+	_, err := io.ReadFull(rand.Reader, pad)
+	return err
+}
+
+type iso7816Padding struct{}
+
+func (iso7816Padding) Pad(text []byte, blockSize int) ([]byte, error) {
+	if err := checkBlockSize(blockSize, 0); err != nil {
+		return []byte{}, err
+	}
+
+	padLength := blockSize - len(text)%blockSize
+	out := make([]byte, len(text)+padLength)
+	copy(out, text)
+	out[len(text)] = 0x80
+
+	return out, nil
+}
+
+func (iso7816Padding) Unpad(text []byte, blockSize int) ([]byte, error) {
+	if err := checkBlockSize(blockSize, 0); err != nil {
+		return []byte{}, err
+	}
+	if err := checkPadded(text, blockSize); err != nil {
+		return []byte{}, err
+	}
+
+	// padding is within the last block, and ends at the first byte that
+	// isn't zero, which must be 0x80
+	for i := len(text) - 1; i >= len(text)-blockSize; i-- {
+		if text[i] == 0x80 {
+			return text[:i], nil
+		}
+		if text[i] != 0x00 {
+			break
+		}
+	}
+
+	return []byte{}, ErrInvalidPadding
+}
+
+type zeroPadding struct{}
+
+func (zeroPadding) Pad(text []byte, blockSize int) ([]byte, error) {
+	if err := checkBlockSize(blockSize, 0); err != nil {
+		return []byte{}, err
+	}
+
+	padLength := (blockSize - len(text)%blockSize) % blockSize
+	out := make([]byte, len(text)+padLength)
+	copy(out, text)
+
+	return out, nil
+}
+
+func (zeroPadding) Unpad(text []byte, blockSize int) ([]byte, error) {
+	if err := checkBlockSize(blockSize, 0); err != nil {
+		return []byte{}, err
+	}
+	if len(text)%blockSize != 0 {
+		return []byte{}, ErrInvalidPadding
+	}
+
+	return bytes.TrimRight(text, "\x00"), nil
+}
@@ -0,0 +1,125 @@
+package cryptopals_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	. "github.com/dcarley/cryptopals"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Padding", func() {
+	DescribeTable("Pad",
+		func(padding Padding, text string, blockSize int, expected string) {
+			out, err := padding.Pad([]byte(text), blockSize)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(out)).To(Equal(expected))
+		},
+		Entry("PKCS#7", PKCS7, "YELLOW SUBMARINE", 20, "YELLOW SUBMARINE\x04\x04\x04\x04"),
+		Entry("PKCS#7 whole block", PKCS7, "YELLOW SUBMARINE", 8, "YELLOW SUBMARINE\x08\x08\x08\x08\x08\x08\x08\x08"),
+		Entry("ANSI X9.23", ANSIX923, "YELLOW SUBMARINE", 20, "YELLOW SUBMARINE\x00\x00\x00\x04"),
+		Entry("ANSI X9.23 whole block", ANSIX923, "YELLOW SUBMARINE", 8, "YELLOW SUBMARINE\x00\x00\x00\x00\x00\x00\x00\x08"),
+		Entry("ISO/IEC 7816-4", ISO7816, "YELLOW SUBMARINE", 20, "YELLOW SUBMARINE\x80\x00\x00\x00"),
+		Entry("ISO/IEC 7816-4 one byte", ISO7816, "YELLOW SUBMARIN", 8, "YELLOW SUBMARIN\x80"),
+		Entry("ISO/IEC 7816-4 whole block", ISO7816, "YELLOW SUBMARINE", 8, "YELLOW SUBMARINE\x80\x00\x00\x00\x00\x00\x00\x00"),
+		Entry("ISO/IEC 7816-4 block too big to count", ISO7816, "YELLOW", 300, "YELLOW\x80"+string(make([]byte, 293))),
+		Entry("zero", ZeroPadding, "YELLOW SUBMARINE", 20, "YELLOW SUBMARINE\x00\x00\x00\x00"),
+		Entry("zero whole block", ZeroPadding, "YELLOW SUBMARINE", 8, "YELLOW SUBMARINE"),
+	)
+
+	It("should pad ISO 10126 with random bytes followed by the length", func() {
+		one, err := ISO10126.Pad([]byte("YELLOW SUBMARINE"), 16)
+		Expect(err).ToNot(HaveOccurred())
+		two, err := ISO10126.Pad([]byte("YELLOW SUBMARINE"), 16)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(one).To(HaveLen(32))
+		Expect(one).To(HavePrefix("YELLOW SUBMARINE"))
+		Expect(one[31]).To(BeEquivalentTo(16))
+		Expect(one).ToNot(Equal(two))
+	})
+
+	DescribeTable("should round trip",
+		func(padding Padding) {
+			for size := 0; size <= 17; size++ {
+				text := bytes.Repeat([]byte{0x80}, size)
+				out, err := padding.Pad(text, 8)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(out) % 8).To(Equal(0))
+
+				out, err = padding.Unpad(out, 8)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(out).To(Equal(text))
+			}
+		},
+		Entry("PKCS#7", PKCS7),
+		Entry("ANSI X9.23", ANSIX923),
+		Entry("ISO 10126", ISO10126),
+		Entry("ISO/IEC 7816-4", ISO7816),
+		Entry("zero", ZeroPadding),
+	)
+
+	It("should not modify the text when padding", func() {
+		text := make([]byte, 5, 8)
+		_, err := ANSIX923.Pad(text, 8)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(text[:8]).To(Equal(make([]byte, 8)))
+	})
+
+	It("should remove trailing zeros that were in the text with zero padding", func() {
+		out, err := ZeroPadding.Unpad([]byte("YELLOW\x00\x00"), 4)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(out)).To(Equal("YELLOW"))
+	})
+
+	DescribeTable("Unpad errors",
+		func(padding Padding, text string, blockSize int) {
+			out, err := padding.Unpad([]byte(text), blockSize)
+			Expect(err).To(Equal(ErrInvalidPadding))
+			Expect(out).To(Equal([]byte{}))
+		},
+		Entry("PKCS#7 empty", PKCS7, "", 4),
+		Entry("PKCS#7 partial block", PKCS7, "YELLOW\x02", 4),
+		Entry("PKCS#7 wrong value", PKCS7, "YELLOW\x03\x03", 4),
+		Entry("PKCS#7 greater than block size", PKCS7, "YELLOW\x05\x05\x05\x05\x05", 4),
+		Entry("ANSI X9.23 not zeros", ANSIX923, "YELLOW\x01\x02", 4),
+		Entry("ANSI X9.23 zero length", ANSIX923, "YELLOW\x00\x00", 4),
+		Entry("ISO 10126 greater than block size", ISO10126, "YELLOW\x01\x05", 4),
+		Entry("ISO/IEC 7816-4 no marker", ISO7816, "YELLOW\x00\x00", 4),
+		Entry("ISO/IEC 7816-4 marker before last block", ISO7816, "YELL\x80\x00\x00\x00\x00\x00\x00\x00", 4),
+		Entry("ISO/IEC 7816-4 not zeros after marker", ISO7816, "YELLOW\x80\x01", 4),
+		Entry("zero partial block", ZeroPadding, "YELLOW", 4),
+	)
+
+	It("should return a PaddingError that matches ErrInvalidPadding", func() {
+		_, err := PKCS7.Unpad([]byte("YELLOW\x03\x03"), 4)
+		wrapped := fmt.Errorf("decrypting: %w", err)
+		Expect(errors.Is(wrapped, ErrInvalidPadding)).To(BeTrue())
+
+		var paddingErr PaddingError
+		Expect(errors.As(wrapped, &paddingErr)).To(BeTrue())
+		Expect(wrapped).To(MatchError("decrypting: invalid padding"))
+	})
+
+	It("should only check the length with ISO 10126", func() {
+		out, err := ISO10126.Unpad([]byte("YELLOW\x01\x02"), 4)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(out)).To(Equal("YELLOW"))
+	})
+
+	DescribeTable("block size errors",
+		func(padding Padding, blockSize int, message string) {
+			_, err := padding.Pad([]byte("text"), blockSize)
+			Expect(err).To(MatchError(message))
+			_, err = padding.Unpad([]byte("text"), blockSize)
+			Expect(err).To(MatchError(message))
+		},
+		Entry("zero", PKCS7, 0, "block size must be positive: 0"),
+		Entry("negative", ZeroPadding, -1, "block size must be positive: -1"),
+		Entry("too big to count", ANSIX923, 256, "block size must not be more than 255: 256"),
+	)
+})
@@ -0,0 +1,697 @@
+package cryptopals
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Transform converts some bytes into other bytes, such as by encoding,
+// decoding or decrypting them.
+type Transform interface {
+	Transform(text []byte) ([]byte, error)
+}
+
+// TransformFunc allows an ordinary function to be used as a Transform.
+type TransformFunc func(text []byte) ([]byte, error)
+
+// Transform calls f(text).
+func (f TransformFunc) Transform(text []byte) ([]byte, error) {
+	return f(text)
+}
+
+// StreamTransform is a Transform that can also work on a stream, without
+// reading all of it into memory first.
+type StreamTransform interface {
+	Transform
+	TransformReader(r io.Reader) io.Reader
+}
+
+// Pipeline is a sequence of transforms, where the output of each one is the
+// input to the next.
+type Pipeline []Transform
+
+// Transform passes text through each transform in turn. Errors are prefixed
+// with the transform that caused them.
+func (p Pipeline) Transform(text []byte) ([]byte, error) {
+	for i, t := range p {
+		var err error
+		if text, err = t.Transform(text); err != nil {
+			return []byte{}, &TransformError{Name: transformName(i, t), Err: err}
+		}
+	}
+
+	return text, nil
+}
+
+// TransformReader returns a reader of r passed through each transform in
+// turn. Transforms that implement StreamTransform process the data as it's
+// read, the others need to read all of their input first.
+func (p Pipeline) TransformReader(r io.Reader) io.Reader {
+	for i, t := range p {
+		if stream, ok := t.(StreamTransform); ok {
+			r = stream.TransformReader(r)
+		} else {
+			r = &bufferedTransformReader{transform: t, r: r}
+		}
+
+		r = &namedReader{name: transformName(i, t), r: r}
+	}
+
+	return r
+}
+
+// Copy writes everything from r to w after passing it through the pipeline.
+func (p Pipeline) Copy(w io.Writer, r io.Reader) (int64, error) {
+	return io.Copy(w, p.TransformReader(r))
+}
+
+// TransformError records which transform in a pipeline caused an error.
+type TransformError struct {
+	Name string
+	Err  error
+}
+
+func (e *TransformError) Error() string {
+	return e.Name + ": " + e.Err.Error()
+}
+
+func (e *TransformError) Unwrap() error {
+	return e.Err
+}
+
+// transformName returns a name for a transform to use in errors.
+func transformName(index int, t Transform) string {
+	if stringer, ok := t.(fmt.Stringer); ok {
+		return stringer.String()
+	}
+
+	return fmt.Sprintf("transform %d", index)
+}
+
+// namedReader prefixes errors from an underlying reader with the name of the
+// transform, the same as Pipeline.Transform does. Errors that have already
+// been prefixed by an earlier transform are passed through, because they
+// travel down the rest of the pipeline.
+type namedReader struct {
+	name string
+	r    io.Reader
+}
+
+func (n *namedReader) Read(p []byte) (int, error) {
+	read, err := n.r.Read(p)
+	if _, ok := err.(*TransformError); !ok && err != nil && err != io.EOF {
+		err = &TransformError{Name: n.name, Err: err}
+	}
+
+	return read, err
+}
+
+// bufferedTransformReader allows a Transform that can't stream to be used in
+// a stream, by reading all of its input on the first read.
+type bufferedTransformReader struct {
+	transform Transform
+	r         io.Reader
+	out       *bytes.Reader
+}
+
+func (b *bufferedTransformReader) Read(p []byte) (int, error) {
+	if b.out == nil {
+		in, err := ioutil.ReadAll(b.r)
+		if err != nil {
+			return 0, err
+		}
+
+		out, err := b.transform.Transform(in)
+		if err != nil {
+			return 0, err
+		}
+
+		b.out = bytes.NewReader(out)
+	}
+
+	return b.out.Read(p)
+}
+
+// TransformArgs are the arguments given to a transform in a pipeline spec,
+// such as "key=ICE". Each method removes the argument that it reads, so
+// that any left over can be reported as unknown.
+type TransformArgs map[string]string
+
+// Bytes returns the value of an argument as bytes. Values prefixed with
+// "hex:" or "b64:" are decoded first, so that keys don't need to be
+// printable. An error is returned if it's required but missing.
+func (a TransformArgs) Bytes(name string, required bool) ([]byte, error) {
+	value, ok := a[name]
+	if !ok {
+		if required {
+			return []byte{}, fmt.Errorf("missing argument: %s", name)
+		}
+
+		return []byte{}, nil
+	}
+	delete(a, name)
+
+	switch {
+	case strings.HasPrefix(value, "hex:"):
+		return HexDecode([]byte(strings.TrimPrefix(value, "hex:")))
+	case strings.HasPrefix(value, "b64:"):
+		return Base64Decode([]byte(strings.TrimPrefix(value, "b64:")))
+	}
+
+	return []byte(value), nil
+}
+
+// Int returns the value of an argument as an int, or def if it's missing.
+func (a TransformArgs) Int(name string, def int) (int, error) {
+	value, ok := a[name]
+	if !ok {
+		return def, nil
+	}
+	delete(a, name)
+
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("argument %s must be a number: %s", name, value)
+	}
+
+	return i, nil
+}
+
+// Bool returns the value of an argument as a bool, or def if it's missing.
+func (a TransformArgs) Bool(name string, def bool) (bool, error) {
+	value, ok := a[name]
+	if !ok {
+		return def, nil
+	}
+	delete(a, name)
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("argument %s must be true or false: %s", name, value)
+	}
+
+	return b, nil
+}
+
+// String returns the value of an argument, or def if it's missing.
+func (a TransformArgs) String(name string, def string) string {
+	value, ok := a[name]
+	if !ok {
+		return def
+	}
+	delete(a, name)
+
+	return value
+}
+
+// TransformConstructor makes a Transform from the arguments in a pipeline
+// spec.
+type TransformConstructor func(args TransformArgs) (Transform, error)
+
+// transforms are the transforms that can be used in a pipeline spec, by
+// name.
+var transforms = map[string]TransformConstructor{}
+
+// RegisterTransform makes a transform available to ParsePipeline by name,
+// replacing any existing transform with the same name.
+func RegisterTransform(name string, constructor TransformConstructor) {
+	transforms[name] = constructor
+}
+
+// TransformNames returns the names of all the transforms that can be used
+// in a pipeline spec, in alphabetical order.
+func TransformNames() []string {
+	names := make([]string, 0, len(transforms))
+	for name := range transforms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// codecAliases are short names for pairs of "-decode" and "-encode"
+// transforms, such as "b64" for "b64-decode" and "b64-encode".
+var codecAliases = map[string]bool{
+	"hex": true,
+	"b64": true,
+	"b32": true,
+	"a85": true,
+}
+
+// resolveTransformName returns the name of the transform that name refers
+// to at position i of a spec. Codec aliases decode when they're the first
+// transform, which reads the encoded input, and encode anywhere else.
+func resolveTransformName(name string, i int) string {
+	if !codecAliases[name] {
+		return name
+	}
+	if i == 0 {
+		return name + "-decode"
+	}
+
+	return name + "-encode"
+}
+
+// ParsePipeline makes a pipeline from a spec, which is a list of transforms
+// separated by "|", each of which may have arguments:
+//
+//	b64-decode | aes-ecb-decrypt key="YELLOW SUBMARINE" | pkcs7-strip | hex-encode
+//
+// The codecs hex, b64, b32 and a85 can be given without "-decode" or
+// "-encode", in which case they decode if they're the first transform and
+// encode otherwise, so this is the same:
+//
+//	b64 | aes-ecb-decrypt key="YELLOW SUBMARINE" | pkcs7-strip | hex
+//
+// Argument values can be quoted with single or double quotes if they
+// contain spaces or "|".
+func ParsePipeline(spec string) (Pipeline, error) {
+	stages, err := splitPipelineSpec(spec)
+	if err != nil {
+		return Pipeline{}, err
+	}
+
+	pipeline := make(Pipeline, 0, len(stages))
+	for i, fields := range stages {
+		if len(fields) == 0 {
+			return Pipeline{}, fmt.Errorf("empty transform at position %d", i)
+		}
+
+		name := fields[0]
+		constructor, ok := transforms[resolveTransformName(name, i)]
+		if !ok {
+			return Pipeline{}, fmt.Errorf("unknown transform: %s", name)
+		}
+
+		args := TransformArgs{}
+		for _, field := range fields[1:] {
+			parts := strings.SplitN(field, "=", 2)
+			if len(parts) != 2 {
+				return Pipeline{}, fmt.Errorf("%s: argument must be name=value: %s", name, field)
+			}
+			args[parts[0]] = parts[1]
+		}
+
+		t, err := constructor(args)
+		if err != nil {
+			return Pipeline{}, fmt.Errorf("%s: %w", name, err)
+		}
+
+		if len(args) > 0 {
+			unknown := make([]string, 0, len(args))
+			for arg := range args {
+				unknown = append(unknown, arg)
+			}
+			sort.Strings(unknown)
+
+			return Pipeline{}, fmt.Errorf("%s: unknown argument: %s", name, strings.Join(unknown, ", "))
+		}
+
+		pipeline = append(pipeline, &namedTransform{name: name, transform: t})
+	}
+
+	return pipeline, nil
+}
+
+// splitPipelineSpec splits a spec into the fields for each stage, removing
+// quotes from around values.
+func splitPipelineSpec(spec string) ([][]string, error) {
+	var (
+		stages  = [][]string{{}}
+		field   strings.Builder
+		inField bool // whether we've started a field, which may be empty quotes
+		quote   rune // quote character that we're inside, if any
+	)
+
+	endField := func() {
+		if inField {
+			stages[len(stages)-1] = append(stages[len(stages)-1], field.String())
+			field.Reset()
+			inField = false
+		}
+	}
+
+	for _, char := range spec {
+		switch {
+		case quote != 0 && char == quote:
+			quote = 0
+		case quote != 0:
+			field.WriteRune(char)
+		case char == '"' || char == '\'':
+			quote = char
+			inField = true
+		case char == '|':
+			endField()
+			stages = append(stages, []string{})
+		case char == ' ' || char == '\t' || char == '\n':
+			endField()
+		default:
+			field.WriteRune(char)
+			inField = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote in pipeline: %c", quote)
+	}
+	endField()
+
+	return stages, nil
+}
+
+// namedTransform records the name that a transform was given in a pipeline
+// spec, so that errors can refer to it.
+type namedTransform struct {
+	name      string
+	transform Transform
+}
+
+func (n *namedTransform) Transform(text []byte) ([]byte, error) {
+	return n.transform.Transform(text)
+}
+
+func (n *namedTransform) String() string {
+	return n.name
+}
+
+// TransformReader streams if the underlying transform can, otherwise it
+// reads all of the input first.
+func (n *namedTransform) TransformReader(r io.Reader) io.Reader {
+	if stream, ok := n.transform.(StreamTransform); ok {
+		return stream.TransformReader(r)
+	}
+
+	return &bufferedTransformReader{transform: n.transform, r: r}
+}
+
+// streamTransform is used to declare the built-in transforms that can
+// stream.
+type streamTransform struct {
+	transform TransformFunc
+	reader    func(r io.Reader) io.Reader
+}
+
+func (s *streamTransform) Transform(text []byte) ([]byte, error) {
+	return s.transform(text)
+}
+
+func (s *streamTransform) TransformReader(r io.Reader) io.Reader {
+	return s.reader(r)
+}
+
+// encodingReader turns a streaming encoder, which is a writer, into a reader
+// by copying to it in the background.
+func encodingReader(r io.Reader, newEncoder func(w io.Writer) io.WriteCloser) io.Reader {
+	pr, pw := io.Pipe()
+	go func() {
+		encoder := newEncoder(pw)
+		_, err := io.Copy(encoder, r)
+		if err == nil {
+			err = encoder.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	return pr
+}
+
+// nopWriteCloser adds a Close method that does nothing to a writer.
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// paddingArg returns the padding scheme chosen by the "scheme" argument.
+func paddingArg(args TransformArgs) (Padding, error) {
+	scheme := args.String("scheme", "pkcs7")
+	padding, ok := paddings[scheme]
+	if !ok {
+		return nil, fmt.Errorf("unknown padding scheme: %s", scheme)
+	}
+
+	return padding, nil
+}
+
+// paddingTransform returns a transform which adds or removes padding for
+// the block size chosen by the "size" argument.
+func paddingTransform(args TransformArgs, padding Padding, pad bool) (Transform, error) {
+	blockSize, err := args.Int("size", 16)
+	if err != nil {
+		return nil, err
+	}
+
+	return TransformFunc(func(text []byte) ([]byte, error) {
+		if pad {
+			return padding.Pad(text, blockSize)
+		}
+
+		return padding.Unpad(text, blockSize)
+	}), nil
+}
+
+// base64EncodingArg returns the base64 encoding chosen by the "alphabet"
+// and "padding" arguments.
+func base64EncodingArg(args TransformArgs) (*Base64Encoding, error) {
+	alphabet := args.String("alphabet", "std")
+	padded := args.String("padding", "true")
+
+	switch {
+	case alphabet == "std" && padded == "true":
+		return StdBase64, nil
+	case alphabet == "std" && padded == "false":
+		return RawStdBase64, nil
+	case alphabet == "url" && padded == "true":
+		return URLBase64, nil
+	case alphabet == "url" && padded == "false":
+		return RawURLBase64, nil
+	}
+
+	return nil, fmt.Errorf("alphabet must be std or url and padding must be true or false: %s, %s", alphabet, padded)
+}
+
+func init() {
+	RegisterTransform("hex-encode", func(args TransformArgs) (Transform, error) {
+		return &streamTransform{
+			transform: func(text []byte) ([]byte, error) {
+				return HexEncode(text), nil
+			},
+			reader: func(r io.Reader) io.Reader {
+				return encodingReader(r, func(w io.Writer) io.WriteCloser {
+					return nopWriteCloser{NewHexEncoder(w)}
+				})
+			},
+		}, nil
+	})
+
+	RegisterTransform("hex-decode", func(args TransformArgs) (Transform, error) {
+		return &streamTransform{
+			transform: HexDecode,
+			reader:    NewHexDecoder,
+		}, nil
+	})
+
+	RegisterTransform("b64-encode", func(args TransformArgs) (Transform, error) {
+		enc, err := base64EncodingArg(args)
+		if err != nil {
+			return nil, err
+		}
+
+		lineLength, err := args.Int("wrap", 0)
+		if err != nil {
+			return nil, err
+		}
+
+		return &streamTransform{
+			transform: func(text []byte) ([]byte, error) {
+				var out bytes.Buffer
+				encoder := enc.NewEncoder(&out, lineLength)
+				if _, err := encoder.Write(text); err != nil {
+					return []byte{}, err
+				}
+				if err := encoder.Close(); err != nil {
+					return []byte{}, err
+				}
+
+				return out.Bytes(), nil
+			},
+			reader: func(r io.Reader) io.Reader {
+				return encodingReader(r, func(w io.Writer) io.WriteCloser {
+					return enc.NewEncoder(w, lineLength)
+				})
+			},
+		}, nil
+	})
+
+	RegisterTransform("b64-decode", func(args TransformArgs) (Transform, error) {
+		enc, err := base64EncodingArg(args)
+		if err != nil {
+			return nil, err
+		}
+
+		return &streamTransform{
+			transform: enc.Decode,
+			reader:    enc.NewDecoder,
+		}, nil
+	})
+
+	RegisterTransform("b32-encode", func(args TransformArgs) (Transform, error) {
+		return TransformFunc(func(text []byte) ([]byte, error) {
+			return Base32Encode(text), nil
+		}), nil
+	})
+
+	RegisterTransform("b32-decode", func(args TransformArgs) (Transform, error) {
+		return TransformFunc(Base32Decode), nil
+	})
+
+	RegisterTransform("a85-encode", func(args TransformArgs) (Transform, error) {
+		return TransformFunc(func(text []byte) ([]byte, error) {
+			return Ascii85Encode(text), nil
+		}), nil
+	})
+
+	RegisterTransform("a85-decode", func(args TransformArgs) (Transform, error) {
+		return TransformFunc(Ascii85Decode), nil
+	})
+
+	RegisterTransform("decode-any", func(args TransformArgs) (Transform, error) {
+		return TransformFunc(func(text []byte) ([]byte, error) {
+			out, _, err := DecodeAny(text)
+			return out, err
+		}), nil
+	})
+
+	RegisterTransform("xor", func(args TransformArgs) (Transform, error) {
+		key, err := args.Bytes("key", true)
+		if err != nil {
+			return nil, err
+		}
+
+		// check the key now, rather than for every stream
+		if _, err := NewXORStream(key); err != nil {
+			return nil, err
+		}
+
+		return &streamTransform{
+			transform: func(text []byte) ([]byte, error) {
+				return RepeatingKeyXOR(text, key)
+			},
+			reader: func(r io.Reader) io.Reader {
+				reader, _ := NewXORReader(r, key)
+				return reader
+			},
+		}, nil
+	})
+
+	RegisterTransform("aes-ecb-encrypt", func(args TransformArgs) (Transform, error) {
+		key, err := args.Bytes("key", true)
+		if err != nil {
+			return nil, err
+		}
+
+		return TransformFunc(func(text []byte) ([]byte, error) {
+			return EncryptAESECB(text, key)
+		}), nil
+	})
+
+	RegisterTransform("aes-ecb-decrypt", func(args TransformArgs) (Transform, error) {
+		key, err := args.Bytes("key", true)
+		if err != nil {
+			return nil, err
+		}
+
+		// padding isn't stripped by default, so that it can be followed by
+		// pkcs7-strip or unpad with another scheme
+		strip, err := args.Bool("strip", false)
+		if err != nil {
+			return nil, err
+		}
+
+		return TransformFunc(func(text []byte) ([]byte, error) {
+			if strip {
+				return DecryptAESECB(text, key)
+			}
+
+			return decryptAESECBBlocks(text, key)
+		}), nil
+	})
+
+	RegisterTransform("aes-ctr", func(args TransformArgs) (Transform, error) {
+		key, err := args.Bytes("key", true)
+		if err != nil {
+			return nil, err
+		}
+		nonce, err := args.Bytes("nonce", false)
+		if err != nil {
+			return nil, err
+		}
+		layoutName := args.String("layout", "64le")
+		layout, ok := ctrLayouts[layoutName]
+		if !ok {
+			return nil, fmt.Errorf("unknown layout: %s", layoutName)
+		}
+		if len(nonce) == 0 {
+			nonce = make([]byte, layout.NonceSize)
+		}
+
+		// check the key and nonce now, rather than for every stream
+		newStream := func() (*CTRStream, error) {
+			ciph, err := aes.NewCipher(key)
+			if err != nil {
+				return nil, err
+			}
+
+			return NewCTRStream(ciph, nonce, layout)
+		}
+		if _, err := newStream(); err != nil {
+			return nil, err
+		}
+
+		return &streamTransform{
+			transform: func(text []byte) ([]byte, error) {
+				return EncryptAESCTR(text, key, nonce, layout)
+			},
+			reader: func(r io.Reader) io.Reader {
+				stream, _ := newStream()
+				return cipher.StreamReader{S: stream, R: r}
+			},
+		}, nil
+	})
+
+	RegisterTransform("pkcs7-pad", func(args TransformArgs) (Transform, error) {
+		return paddingTransform(args, PKCS7, true)
+	})
+
+	RegisterTransform("pkcs7-strip", func(args TransformArgs) (Transform, error) {
+		return paddingTransform(args, PKCS7, false)
+	})
+
+	RegisterTransform("pad", func(args TransformArgs) (Transform, error) {
+		padding, err := paddingArg(args)
+		if err != nil {
+			return nil, err
+		}
+
+		return paddingTransform(args, padding, true)
+	})
+
+	RegisterTransform("unpad", func(args TransformArgs) (Transform, error) {
+		padding, err := paddingArg(args)
+		if err != nil {
+			return nil, err
+		}
+
+		return paddingTransform(args, padding, false)
+	})
+}
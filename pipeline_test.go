@@ -0,0 +1,258 @@
+package cryptopals_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing/iotest"
+
+	. "github.com/dcarley/cryptopals"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Pipeline", func() {
+	Describe("Transform", func() {
+		It("should chain transforms by hand", func() {
+			pipeline := Pipeline{
+				TransformFunc(HexDecode),
+				TransformFunc(func(text []byte) ([]byte, error) {
+					return RepeatingKeyXOR(text, []byte("ICE"))
+				}),
+				TransformFunc(func(text []byte) ([]byte, error) {
+					return Base64Encode(text), nil
+				}),
+			}
+
+			out, err := pipeline.Transform([]byte("0b3637272a2b2e63622c2e69692a23693a2a3c"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(out).To(Equal(Base64Encode([]byte("Burning 'em, if you"))))
+		})
+
+		It("should prefix errors with the position of the transform", func() {
+			pipeline := Pipeline{
+				TransformFunc(Base64Decode),
+				TransformFunc(HexDecode),
+			}
+
+			out, err := pipeline.Transform([]byte("Z2g="))
+			Expect(err).To(MatchError("transform 1: invalid hex character: g at offset 0"))
+			Expect(out).To(Equal([]byte{}))
+
+			var decodeErr *DecodeError
+			Expect(errors.As(err, &decodeErr)).To(BeTrue())
+			Expect(decodeErr.Offset).To(BeEquivalentTo(0))
+		})
+	})
+
+	Describe("ParsePipeline", func() {
+		It("should solve challenge 7", func() {
+			pipeline, err := ParsePipeline(`b64-decode | aes-ecb-decrypt key="YELLOW SUBMARINE" | pkcs7-strip`)
+			Expect(err).ToNot(HaveOccurred())
+
+			b64, err := ioutil.ReadFile("fixtures/s1c7")
+			Expect(err).ToNot(HaveOccurred())
+			plain, err := ioutil.ReadFile("fixtures/s1c7.plain")
+			Expect(err).ToNot(HaveOccurred())
+
+			out, err := pipeline.Transform(b64)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(out).To(Equal(plain))
+		})
+
+		DescribeTable("specs",
+			func(spec, input, expected string) {
+				pipeline, err := ParsePipeline(spec)
+				Expect(err).ToNot(HaveOccurred())
+
+				out, err := pipeline.Transform([]byte(input))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(string(out)).To(Equal(expected))
+			},
+			Entry("decrypt and strip padding as separate steps",
+				`b64-decode | aes-ecb-decrypt key="YELLOW SUBMARINE" | pkcs7-strip | hex-encode`,
+				"eVr6zWJIisWmxzyLn39p3A==",
+				"68656c6c6f20676f70686572",
+			),
+			Entry("decrypt and strip padding with codec aliases",
+				`b64 | aes-ecb-decrypt key="YELLOW SUBMARINE" | pkcs7-strip | hex`,
+				"eVr6zWJIisWmxzyLn39p3A==",
+				"68656c6c6f20676f70686572",
+			),
+			Entry("codec aliases with arguments",
+				"hex | b64 alphabet=url padding=false | b32",
+				"fbff",
+				"FVPTQ===",
+			),
+			Entry("decrypt without stripping padding",
+				`b64-decode | aes-ecb-decrypt key="YELLOW SUBMARINE"`,
+				"eVr6zWJIisWmxzyLn39p3A==",
+				"hello gopher\x04\x04\x04\x04",
+			),
+			Entry("decrypt and strip padding in one step",
+				`b64-decode | aes-ecb-decrypt key="YELLOW SUBMARINE" strip=true`,
+				"eVr6zWJIisWmxzyLn39p3A==",
+				"hello gopher",
+			),
+			Entry("challenge 5",
+				"xor key=ICE | hex-encode",
+				"Burning 'em, if you ain't quick and nimble",
+				"0b3637272a2b2e63622c2e69692a23693a2a3c6324202d623d63343c2a26226324272765272a282b2f20",
+			),
+			Entry("key given in hex",
+				"hex-decode | xor key=hex:494345",
+				"0b3637272a2b2e6362",
+				"Burning '",
+			),
+			Entry("single quoted argument containing a pipe",
+				"xor key='a|b' | xor key='a|b'",
+				"hello gopher",
+				"hello gopher",
+			),
+			Entry("URL safe base64 without padding",
+				"b64-encode alphabet=url padding=false",
+				"\xfb\xff",
+				"-_8",
+			),
+			Entry("AES ECB round trip",
+				`aes-ecb-encrypt key="YELLOW SUBMARINE" | b64-encode | b64-decode | aes-ecb-decrypt key="YELLOW SUBMARINE" strip=true`,
+				"hello gopher",
+				"hello gopher",
+			),
+			Entry("challenge 18",
+				`b64-decode | aes-ctr key="YELLOW SUBMARINE"`,
+				"L77na/nrFsKvynd6HzOoG7GHTLXsTVu9qvY/2syLXzhPweyyMTJULu/6/kXX0KSvoOLSFQ==",
+				"Yo, VIP Let's kick it Ice, Ice, baby Ice, Ice, baby ",
+			),
+			Entry("AES CTR round trip with a 96 bit nonce",
+				`aes-ctr key="YELLOW SUBMARINE" nonce=hex:000102030405060708090a0b layout=96be | aes-ctr key="YELLOW SUBMARINE" nonce=hex:000102030405060708090a0b layout=96be`,
+				"hello gopher",
+				"hello gopher",
+			),
+			Entry("wrapped base64",
+				"b64-encode wrap=8",
+				"hello gopher",
+				"aGVsbG8g\nZ29waGVy\n",
+			),
+			Entry("base32 and ascii85",
+				"b32-encode | b32-decode | a85-encode | a85-decode",
+				"hello gopher",
+				"hello gopher",
+			),
+			Entry("detected encoding",
+				"decode-any | pkcs7-pad size=8",
+				"68656c6c6f",
+				"hello\x03\x03\x03",
+			),
+			Entry("padding schemes",
+				"pad scheme=x923 size=8 | pad scheme=iso7816 size=4 | unpad scheme=iso7816 size=4 | unpad scheme=x923 size=8",
+				"hello gopher",
+				"hello gopher",
+			),
+			Entry("PKCS#7 padding a whole block",
+				"pkcs7-pad size=4 | hex-encode",
+				"gophers!",
+				"676f70686572732104040404",
+			),
+		)
+
+		DescribeTable("errors",
+			func(spec, expected string) {
+				pipeline, err := ParsePipeline(spec)
+				Expect(err).To(MatchError(expected))
+				Expect(pipeline).To(BeEmpty())
+			},
+			Entry("unknown transform", "hex-encode | rot13", "unknown transform: rot13"),
+			Entry("codec alias argument", "hex size=1", "hex: unknown argument: size"),
+			Entry("empty transform", "hex-encode || hex-decode", "empty transform at position 1"),
+			Entry("missing argument", "xor", "xor: missing argument: key"),
+			Entry("unknown arguments", "hex-encode size=1 key=2", "hex-encode: unknown argument: key, size"),
+			Entry("argument without value", "xor ICE", "xor: argument must be name=value: ICE"),
+			Entry("argument that isn't a number", "pkcs7-pad size=big", "pkcs7-pad: argument size must be a number: big"),
+			Entry("unterminated quote", `xor key="ICE`, `unterminated quote in pipeline: "`),
+			Entry("empty key", `xor key=""`, "xor: key must not be empty"),
+			Entry("argument that isn't a bool", `aes-ecb-decrypt key="YELLOW SUBMARINE" strip=maybe`, "aes-ecb-decrypt: argument strip must be true or false: maybe"),
+			Entry("unknown padding scheme", "pad scheme=rot13", "pad: unknown padding scheme: rot13"),
+			Entry("unknown CTR layout", `aes-ctr key="YELLOW SUBMARINE" layout=128le`, "aes-ctr: unknown layout: 128le"),
+			Entry("wrong CTR nonce size", `aes-ctr key="YELLOW SUBMARINE" nonce=abc`, "aes-ctr: nonce must be 8 bytes: 3"),
+		)
+
+		It("should prefix errors with the name of the transform", func() {
+			pipeline, err := ParsePipeline("hex-decode | b64-decode")
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = pipeline.Transform([]byte("2121"))
+			Expect(err).To(MatchError("b64-decode: invalid base64 character: ! at offset 0"))
+		})
+
+		It("should return ErrInvalidPadding when padding can't be removed", func() {
+			pipeline, err := ParsePipeline("hex-decode | pkcs7-strip size=4")
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = pipeline.Transform([]byte("676f7068657273"))
+			Expect(err).To(MatchError("pkcs7-strip: invalid padding"))
+			Expect(errors.Is(err, ErrInvalidPadding)).To(BeTrue())
+		})
+
+		It("should list the transforms that are available", func() {
+			Expect(TransformNames()).To(ContainElement("aes-ecb-decrypt"))
+		})
+	})
+
+	Describe("TransformReader", func() {
+		It("should stream the same output as Transform", func() {
+			pipeline, err := ParsePipeline(`b64-decode | aes-ecb-decrypt key="YELLOW SUBMARINE" | pkcs7-strip | hex-encode | hex-decode | b64-encode wrap=60`)
+			Expect(err).ToNot(HaveOccurred())
+
+			b64, err := ioutil.ReadFile("fixtures/s1c7")
+			Expect(err).ToNot(HaveOccurred())
+			expected, err := pipeline.Transform(b64)
+			Expect(err).ToNot(HaveOccurred())
+
+			file, err := os.Open("fixtures/s1c7")
+			Expect(err).ToNot(HaveOccurred())
+			defer file.Close()
+
+			var out bytes.Buffer
+			_, err = pipeline.Copy(&out, iotest.HalfReader(file))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(out.Bytes()).To(Equal(expected))
+		})
+
+		It("should stream XOR without losing its place in the key", func() {
+			pipeline, err := ParsePipeline("xor key=ICE | hex-encode")
+			Expect(err).ToNot(HaveOccurred())
+
+			var out bytes.Buffer
+			_, err = pipeline.Copy(&out, iotest.OneByteReader(strings.NewReader("Burning 'em, if you ain't quick and nimble")))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(out.String()).To(Equal("0b3637272a2b2e63622c2e69692a23693a2a3c6324202d623d63343c2a26226324272765272a282b2f20"))
+		})
+
+		It("should stream AES CTR the same as Transform", func() {
+			pipeline, err := ParsePipeline(`aes-ctr key="YELLOW SUBMARINE" | hex-encode`)
+			Expect(err).ToNot(HaveOccurred())
+
+			plain := "Burning 'em, if you ain't quick and nimble"
+			expected, err := pipeline.Transform([]byte(plain))
+			Expect(err).ToNot(HaveOccurred())
+
+			var out bytes.Buffer
+			_, err = pipeline.Copy(&out, iotest.OneByteReader(strings.NewReader(plain)))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(out.Bytes()).To(Equal(expected))
+		})
+
+		It("should prefix errors with the name of the transform", func() {
+			pipeline, err := ParsePipeline("hex-decode | hex-encode")
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = ioutil.ReadAll(pipeline.TransformReader(strings.NewReader("6162zz")))
+			Expect(err).To(MatchError("hex-decode: invalid hex character: z at offset 4"))
+		})
+	})
+})
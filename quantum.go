@@ -0,0 +1,167 @@
+package cryptopals
+
+import (
+	"fmt"
+)
+
+// quantumEncoding is the part of base64 and base32 that decodes them. Both
+// split their input into groups of characters, called quanta, which each
+// decode to a fixed number of bytes, and can pad the last quantum with '='.
+type quantumEncoding struct {
+	// name is used in errors about invalid characters
+	name string
+	// decodeMap is used to lookup zero-indexed values using characters,
+	// the reverse of the alphabet
+	decodeMap [256]byte
+	padding   PadPolicy
+	// dataChars is the number of characters before the padding in a
+	// quantum, indexed by how many bytes it contains
+	dataChars []int
+	// appendQuantum decodes the values of a whole quantum and appends the
+	// first size bytes of it to out
+	appendQuantum func(out, quantum []byte, size int) []byte
+}
+
+// setAlphabet fills in the decode map for alphabet, which can't contain
+// duplicates, the padding character '=' or whitespace.
+func (e *quantumEncoding) setAlphabet(alphabet string) error {
+	for i := range e.decodeMap {
+		e.decodeMap[i] = invalidChar
+	}
+	for i := 0; i < len(alphabet); i++ {
+		char := alphabet[i]
+		switch {
+		case char == '=' || isWhitespace(char):
+			// the decoder skips whitespace, so it would be lost
+			return fmt.Errorf("alphabet contains reserved character: %q", char)
+		case e.decodeMap[char] != invalidChar:
+			return fmt.Errorf("alphabet contains duplicate character: %q", char)
+		}
+
+		e.decodeMap[char] = byte(i)
+	}
+
+	return nil
+}
+
+// quantumSize returns the number of characters in a whole quantum.
+func (e *quantumEncoding) quantumSize() int {
+	return e.dataChars[len(e.dataChars)-1]
+}
+
+// size returns the number of bytes encoded by a number of characters in a
+// quantum, or -1 if it isn't a valid number of characters.
+func (e *quantumEncoding) size(chars int) int {
+	for size := 1; size < len(e.dataChars); size++ {
+		if e.dataChars[size] == chars {
+			return size
+		}
+	}
+
+	return -1
+}
+
+// decode decodes all of text. Malformed input is returned as a
+// *DecodeError with the offset of the problem.
+func (e *quantumEncoding) decode(text []byte) ([]byte, error) {
+	// a whole quantum of bytes out for every quantum of characters in,
+	// which may be slightly too big if there is whitespace or padding
+	out := make([]byte, 0, len(text)/e.quantumSize()*(len(e.dataChars)-1))
+
+	state := quantumState{enc: e}
+	out, err := state.decode(out, text, 0)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	out, err = state.end(out, int64(len(text)))
+	if err != nil {
+		return []byte{}, err
+	}
+
+	return out, nil
+}
+
+// quantumState keeps track of decoding so that the input can be given to it
+// in pieces, such as from a stream.
+type quantumState struct {
+	enc      *quantumEncoding
+	quantum  [8]byte // values of the characters being decoded
+	count    int     // number of characters in quantum
+	padding  int     // number of padding characters in quantum
+	finished bool    // whether we've decoded a padded quantum
+}
+
+// decode decodes text, appends it to out and returns the extended slice.
+// offset is the position of text within the whole input, used for errors.
+// If an error is returned then out contains everything before it.
+func (s *quantumState) decode(out, text []byte, offset int64) ([]byte, error) {
+	quantumSize := s.enc.quantumSize()
+
+	for i, char := range text {
+		if isWhitespace(char) {
+			continue
+		}
+
+		// padding can only appear at the very end of the input
+		if s.finished || (s.padding > 0 && char != '=') {
+			return out, &DecodeError{Offset: offset + int64(i), Reason: "unexpected data after padding"}
+		}
+
+		if char == '=' {
+			// padding can only start after a number of characters that
+			// makes a whole number of bytes
+			if s.enc.padding == PadNone || (s.padding == 0 && s.enc.size(s.count) < 0) {
+				return out, &DecodeError{Offset: offset + int64(i), Reason: "unexpected padding"}
+			}
+
+			s.quantum[s.count] = 0
+			s.padding++
+		} else {
+			v := s.enc.decodeMap[char]
+			if v == invalidChar {
+				return out, &DecodeError{
+					Offset: offset + int64(i),
+					Reason: fmt.Sprintf("invalid %s character: %s", s.enc.name, []byte{char}),
+				}
+			}
+
+			s.quantum[s.count] = v
+		}
+
+		s.count++
+		if s.count < quantumSize {
+			continue
+		}
+
+		out = s.enc.appendQuantum(out, s.quantum[:quantumSize], s.enc.size(quantumSize-s.padding))
+		s.finished = s.padding > 0
+		s.count = 0
+	}
+
+	return out, nil
+}
+
+// end is called when there is no more input, to check that it didn't finish
+// part way through a quantum. offset is the size of the whole input.
+func (s *quantumState) end(out []byte, offset int64) ([]byte, error) {
+	if s.count == 0 {
+		return out, nil
+	}
+
+	// unpadded input can end with a partial quantum, but only if the
+	// characters make a whole number of bytes, and padding that's been
+	// started must fill the quantum
+	if s.enc.padding == PadRequired || s.enc.size(s.count) < 0 || s.padding > 0 {
+		return out, &DecodeError{Offset: offset, Reason: "input is truncated"}
+	}
+
+	quantumSize := s.enc.quantumSize()
+	for i := s.count; i < quantumSize; i++ {
+		s.quantum[i] = 0
+	}
+	out = s.enc.appendQuantum(out, s.quantum[:quantumSize], s.enc.size(s.count))
+	s.count = 0
+
+	return out, nil
+}
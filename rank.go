@@ -0,0 +1,196 @@
+package cryptopals
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// RankedKeyScore is a candidate key from a Ranking.
+type RankedKeyScore struct {
+	KeyScore
+	// Normalised is the score scaled between 0 for the worst candidate that
+	// was tried and 1 for the best.
+	Normalised float64
+}
+
+// Ranking is a list of candidate keys, best first, so that near-misses can
+// be inspected as well as the most likely key.
+type Ranking struct {
+	Candidates []RankedKeyScore
+	// Confidence is how far the best candidate is ahead of the second best,
+	// between 0 when they're tied and 1 when there was only one candidate
+	// or the second best was the worst.
+	Confidence float64
+}
+
+// Best returns the highest scoring candidate.
+func (r Ranking) Best() KeyScore {
+	if len(r.Candidates) == 0 {
+		return KeyScore{}
+	}
+
+	return r.Candidates[0].KeyScore
+}
+
+// RankSingleByteXOR tries every possible single byte key that some text has
+// been XORed against, including those which aren't printable, and returns
+// up to limit of them in order of scorer.
+func RankSingleByteXOR(text []byte, scorer Scorer, limit int) (Ranking, error) {
+	if limit < 1 {
+		return Ranking{}, fmt.Errorf("limit must be positive: %d", limit)
+	}
+
+	scores := make([]KeyScore, 0, 256)
+	for key := 0; key < 256; key++ {
+		out, err := RepeatingKeyXOR(text, []byte{byte(key)})
+		if err != nil {
+			return Ranking{}, err
+		}
+
+		scores = append(scores, KeyScore{
+			Score: scorer.Score(out),
+			Key:   []byte{byte(key)},
+			Text:  out,
+		})
+	}
+
+	return rankKeyScores(scores, limit), nil
+}
+
+// RankMultiByteXOR finds the multi byte key that some text has been XORed
+// against, like BruteForceMultiByteXORWithScorer, and returns up to limit
+// candidates in order of scorer. The candidates are the best key for each
+// likely key size and, for each of those, the keys where one byte has been
+// replaced by the second best byte for that position.
+func RankMultiByteXOR(text []byte, scorer Scorer, limit int) (Ranking, error) {
+	return RankMultiByteXORWithOptions(text, MultiByteXOROptions{
+		KeySizeOptions: DefaultKeySizeOptions,
+		Scorer:         scorer,
+	}, limit)
+}
+
+// RankMultiByteXORWithOptions is like RankMultiByteXOR, but guesses key
+// sizes and scores plaintext with opts, the same as
+// BruteForceMultiByteXORWithOptions.
+func RankMultiByteXORWithOptions(text []byte, opts MultiByteXOROptions, limit int) (Ranking, error) {
+	return RankMultiByteXORContext(context.Background(), text, opts, limit)
+}
+
+// RankMultiByteXORContext is like RankMultiByteXORWithOptions, but returns
+// ctx.Err() if ctx is done before it finishes. opts.Workers isn't used,
+// because the key bytes are found one at a time.
+func RankMultiByteXORContext(ctx context.Context, text []byte, opts MultiByteXOROptions, limit int) (Ranking, error) {
+	if limit < 1 {
+		return Ranking{}, fmt.Errorf("limit must be positive: %d", limit)
+	}
+
+	scorer := opts.Scorer
+	if scorer == nil {
+		scorer = ETAOINScorer
+	}
+
+	keySizes, err := estimateKeySizes(text, opts)
+	if err != nil {
+		return Ranking{}, err
+	}
+
+	var keys [][]byte
+	for _, keySize := range keySizes {
+		best := make([]byte, keySize)
+		runnersUp := make([]byte, keySize)
+		keyBlocks := TransposeBlocks(text, keySize)
+
+		for i := 0; i < keySize; i++ {
+			if err := ctx.Err(); err != nil {
+				return Ranking{}, err
+			}
+
+			ranking, err := RankSingleByteXOR(keyBlocks[i], scorer, 2)
+			if err != nil {
+				return Ranking{}, err
+			}
+
+			best[i] = ranking.Candidates[0].Key[0]
+			runnersUp[i] = ranking.Candidates[1].Key[0]
+		}
+
+		keys = append(keys, best)
+		for i := range runnersUp {
+			nearMiss := append([]byte{}, best...)
+			nearMiss[i] = runnersUp[i]
+			keys = append(keys, nearMiss)
+		}
+	}
+
+	scores := make([]KeyScore, 0, len(keys))
+	for _, key := range keys {
+		out, err := RepeatingKeyXOR(text, key)
+		if err != nil {
+			return Ranking{}, err
+		}
+
+		scores = append(scores, KeyScore{
+			Score: scorer.Score(out),
+			Key:   key,
+			Text:  out,
+		})
+	}
+
+	return rankKeyScores(scores, limit), nil
+}
+
+// rankKeyScores sorts scores, highest first, normalises them and keeps the
+// first limit. The order of equal scores is kept, so that ties are won by
+// the candidate that was tried first.
+func rankKeyScores(scores []KeyScore, limit int) Ranking {
+	sort.SliceStable(scores, func(i, j int) bool {
+		return scores[i].Score > scores[j].Score
+	})
+
+	// infinite scores would make every other score the same, so scale
+	// between the finite ones
+	highest, lowest := math.Inf(-1), math.Inf(1)
+	for _, score := range scores {
+		if !math.IsInf(score.Score, 0) {
+			highest = math.Max(highest, score.Score)
+			lowest = math.Min(lowest, score.Score)
+		}
+	}
+
+	ranking := Ranking{Candidates: make([]RankedKeyScore, 0, limit)}
+	for i, score := range scores {
+		if i == limit {
+			break
+		}
+
+		ranking.Candidates = append(ranking.Candidates, RankedKeyScore{
+			KeyScore:   score,
+			Normalised: normaliseScore(score.Score, lowest, highest),
+		})
+	}
+
+	ranking.Confidence = 1
+	if len(scores) > 1 {
+		ranking.Confidence = normaliseScore(scores[0].Score, lowest, highest) -
+			normaliseScore(scores[1].Score, lowest, highest)
+	}
+
+	return ranking
+}
+
+// normaliseScore scales a score between 0 and 1, clamping infinite scores.
+// When all of the scores are the same they're normalised to 1.
+func normaliseScore(score, lowest, highest float64) float64 {
+	switch {
+	case math.IsInf(score, 1):
+		return 1
+	case math.IsInf(score, -1):
+		return 0
+	case highest == lowest:
+		return 1
+	}
+
+	return (score - lowest) / (highest - lowest)
+}
@@ -0,0 +1,178 @@
+package cryptopals_test
+
+import (
+	"context"
+	"io/ioutil"
+
+	. "github.com/dcarley/cryptopals"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Rank", func() {
+	Describe("RankSingleByteXOR", func() {
+		var xor []byte
+
+		BeforeEach(func() {
+			var err error
+			xor, err = HexDecode([]byte("1b37373331363f78151b7f2b783431333d78397828372d363c78373e783a393b3736"))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should solve challenge 3", func() {
+			ranking, err := RankSingleByteXOR(xor, EnglishChiSquaredScorer, 5)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ranking.Candidates).To(HaveLen(5))
+			Expect(ranking.Best().Key).To(Equal([]byte("X")))
+			Expect(ranking.Best().Text).To(Equal([]byte("Cooking MC's like a pound of bacon")))
+			Expect(ranking.Candidates[0].Normalised).To(Equal(1.0))
+			Expect(ranking.Confidence).To(BeNumerically(">", 0))
+		})
+
+		It("should rank candidates highest first", func() {
+			ranking, err := RankSingleByteXOR(xor, EnglishChiSquaredScorer, 256)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ranking.Candidates).To(HaveLen(256))
+
+			for i := 1; i < len(ranking.Candidates); i++ {
+				Expect(ranking.Candidates[i].Score).To(BeNumerically("<=", ranking.Candidates[i-1].Score))
+				Expect(ranking.Candidates[i].Normalised).To(BeNumerically("<=", ranking.Candidates[i-1].Normalised))
+			}
+			Expect(ranking.Candidates[255].Normalised).To(Equal(0.0))
+		})
+
+		It("should try keys that aren't printable", func() {
+			plain := []byte("now that the party is jumping")
+			xor, err := RepeatingKeyXOR(plain, []byte{0xf0})
+			Expect(err).ToNot(HaveOccurred())
+
+			ranking, err := RankSingleByteXOR(xor, EnglishChiSquaredScorer, 1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ranking.Best().Key).To(Equal([]byte{0xf0}))
+			Expect(ranking.Best().Text).To(Equal(plain))
+		})
+
+		It("should have no confidence when candidates are tied", func() {
+			ranking, err := RankSingleByteXOR(xor, ScorerFunc(func([]byte) float64 { return 1 }), 3)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ranking.Best().Key).To(Equal([]byte{0}))
+			Expect(ranking.Candidates[2].Normalised).To(Equal(1.0))
+			Expect(ranking.Confidence).To(Equal(0.0))
+		})
+
+		It("should error when limit isn't positive", func() {
+			_, err := RankSingleByteXOR(xor, ETAOINScorer, 0)
+			Expect(err).To(MatchError("limit must be positive: 0"))
+		})
+	})
+
+	Describe("RankMultiByteXOR", func() {
+		It("should solve challenge 6 with near-misses", func() {
+			b64, err := ioutil.ReadFile("fixtures/s1c6")
+			Expect(err).ToNot(HaveOccurred())
+			xor, err := Base64Decode(b64)
+			Expect(err).ToNot(HaveOccurred())
+
+			key := []byte("Terminator X: Bring the noise")
+			ranking, err := RankMultiByteXOR(xor, EnglishChiSquaredScorer, 10)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ranking.Candidates).To(HaveLen(10))
+			Expect(ranking.Best().Key).To(Equal(key))
+			Expect(ranking.Confidence).To(BeNumerically(">", 0))
+
+			// the runners up differ from the key by one byte
+			for _, candidate := range ranking.Candidates[1:] {
+				Expect(candidate.Key).To(HaveLen(len(key)))
+
+				var diff int
+				for i := range key {
+					if candidate.Key[i] != key[i] {
+						diff++
+					}
+				}
+				Expect(diff).To(Equal(1), string(candidate.Key))
+			}
+		})
+
+		It("should error when limit isn't positive", func() {
+			_, err := RankMultiByteXOR([]byte{}, ETAOINScorer, -1)
+			Expect(err).To(MatchError("limit must be positive: -1"))
+		})
+
+		Describe("RankMultiByteXORWithOptions and RankMultiByteXORContext", func() {
+			var xor []byte
+
+			BeforeEach(func() {
+				b64, err := ioutil.ReadFile("fixtures/s1c6")
+				Expect(err).ToNot(HaveOccurred())
+				xor, err = Base64Decode(b64)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("should use the key size estimator from the options", func() {
+				key := []byte("Terminator X: Bring the noise")
+				ranking, err := RankMultiByteXORWithOptions(xor, MultiByteXOROptions{
+					KeySizeOptions:   DefaultKeySizeOptions,
+					KeySizeEstimator: GuessXORKeySizeCombined,
+					Scorer:           EnglishChiSquaredScorer,
+				}, 3)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ranking.Best().Key).To(Equal(key))
+
+				score, err := BruteForceMultiByteXORWithOptions(xor, MultiByteXOROptions{
+					KeySizeOptions:   DefaultKeySizeOptions,
+					KeySizeEstimator: GuessXORKeySizeCombined,
+					Scorer:           EnglishChiSquaredScorer,
+				})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ranking.Best()).To(Equal(score))
+			})
+
+			It("should only rank the key sizes from the estimator", func() {
+				ranking, err := RankMultiByteXORWithOptions(xor, MultiByteXOROptions{
+					KeySizeEstimator: func([]byte, KeySizeOptions) ([]int, error) {
+						return []int{5}, nil
+					},
+				}, 10)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ranking.Candidates).To(HaveLen(6))
+				for _, candidate := range ranking.Candidates {
+					Expect(candidate.Key).To(HaveLen(5))
+				}
+			})
+
+			It("should skip key sizes that don't fit the text", func() {
+				ranking, err := RankMultiByteXORWithOptions(xor, MultiByteXOROptions{
+					KeySizeEstimator: func([]byte, KeySizeOptions) ([]int, error) {
+						return []int{0, 5, -1, len(xor) + 1}, nil
+					},
+				}, 10)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ranking.Candidates).To(HaveLen(6))
+				for _, candidate := range ranking.Candidates {
+					Expect(candidate.Key).To(HaveLen(5))
+				}
+			})
+
+			It("should return an error when no key sizes fit the text", func() {
+				_, err := RankMultiByteXORWithOptions([]byte("abcd"), MultiByteXOROptions{
+					KeySizeEstimator: func([]byte, KeySizeOptions) ([]int, error) {
+						return []int{0, 5}, nil
+					},
+				}, 10)
+				Expect(err).To(MatchError("no key sizes between 1 and 4: [0 5]"))
+			})
+
+			It("should stop when the context is cancelled", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				_, err := RankMultiByteXORContext(ctx, xor, MultiByteXOROptions{
+					KeySizeOptions: DefaultKeySizeOptions,
+				}, 10)
+				Expect(err).To(Equal(context.Canceled))
+			})
+		})
+	})
+})
@@ -0,0 +1,369 @@
+package cryptopals
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Scorer scores how likely it is that some text is plaintext, rather than
+// the output of decrypting with the wrong key. Higher scores are more
+// likely. Scores from different scorers can't be compared to each other.
+type Scorer interface {
+	Score(text []byte) float64
+}
+
+// ScorerFunc allows an ordinary function to be used as a Scorer.
+type ScorerFunc func(text []byte) float64
+
+// Score calls f(text).
+func (f ScorerFunc) Score(text []byte) float64 {
+	return f(text)
+}
+
+// ETAOINScorer scores text using ScoreEnglish, which counts the most common
+// letters. It's fast but easily fooled by text that is mostly spaces.
+var ETAOINScorer Scorer = ScorerFunc(func(text []byte) float64 {
+	return float64(ScoreEnglish(text))
+})
+
+// EnglishFrequencies are the proportions of each letter, a to z, in English
+// text: https://en.wikipedia.org/wiki/Letter_frequency
+var EnglishFrequencies = [26]float64{
+	0.08167, 0.01492, 0.02782, 0.04253, 0.12702, 0.02228, 0.02015, // a-g
+	0.06094, 0.06966, 0.00153, 0.00772, 0.04025, 0.02406, 0.06749, // h-n
+	0.07507, 0.01929, 0.00095, 0.05987, 0.06327, 0.09056, 0.02758, // o-u
+	0.00978, 0.02360, 0.00150, 0.01974, 0.00074, // v-z
+}
+
+// Expected proportions of text that aren't letters, used by
+// ChiSquaredScorer. Everything else is expected to be letters.
+const (
+	spaceProportion = 0.17 // roughly one in every six characters
+	otherProportion = 0.03 // digits, punctuation and newlines
+)
+
+// ChiSquaredScorer scores text by how closely the frequency of each letter
+// matches a language, using Pearson's chi-squared test:
+// https://en.wikipedia.org/wiki/Pearson%27s_chi-squared_test
+//
+// Spaces and other characters are included as extra categories, so that
+// text which is mostly spaces or punctuation doesn't score well.
+type ChiSquaredScorer struct {
+	// Frequencies are the proportions of each letter, a to z, which should
+	// add up to 1.
+	Frequencies [26]float64
+	// FoldAccents decodes text as UTF-8 and counts accented letters as
+	// the letter without an accent, for languages other than English.
+	FoldAccents bool
+}
+
+// EnglishChiSquaredScorer compares letter frequencies to English.
+var EnglishChiSquaredScorer = &ChiSquaredScorer{Frequencies: EnglishFrequencies}
+
+// Score returns the chi-squared statistic as a negative number, because
+// lower statistics are a closer match.
+func (c *ChiSquaredScorer) Score(text []byte) float64 {
+	if len(text) == 0 {
+		return math.Inf(-1)
+	}
+
+	// count characters instead of bytes when they could be multi-byte
+	var chars []rune
+	if c.FoldAccents {
+		chars = foldAccents(text)
+	} else {
+		chars = make([]rune, len(text))
+		for i, char := range text {
+			chars[i] = rune(char)
+		}
+	}
+
+	// the first 26 are letters, followed by spaces and everything else
+	var observed [28]float64
+	for _, char := range chars {
+		switch {
+		case char >= 'a' && char <= 'z':
+			observed[char-'a']++
+		case char >= 'A' && char <= 'Z':
+			observed[char-'A']++
+		case char == ' ':
+			observed[26]++
+		default:
+			observed[27]++
+		}
+	}
+
+	size := float64(len(chars))
+	letterProportion := 1 - spaceProportion - otherProportion
+
+	var expected [28]float64
+	for i, freq := range c.Frequencies {
+		expected[i] = freq * letterProportion * size
+	}
+	expected[26] = spaceProportion * size
+	expected[27] = otherProportion * size
+
+	var chiSquared float64
+	for i := range observed {
+		if expected[i] == 0 {
+			continue
+		}
+
+		diff := observed[i] - expected[i]
+		chiSquared += diff * diff / expected[i]
+	}
+
+	return -chiSquared
+}
+
+// NgramScorer scores text by the log-likelihood of each sequence of N
+// letters in it, known as n-grams, such as "th" for bigrams or "tion" for
+// quadgrams: http://practicalcryptography.com/cryptanalysis/text-characterisation/quadgrams/
+type NgramScorer struct {
+	N int
+	// LogProbs are the base 10 logarithm of the probability of each n-gram,
+	// in lowercase.
+	LogProbs map[string]float64
+	// Floor is the log probability used for n-grams that aren't in
+	// LogProbs, which is low but not impossible.
+	Floor float64
+	// KeepSpaces includes whitespace in n-grams, as a single space.
+	// Otherwise whitespace is removed, which is how most published tables
+	// are counted.
+	KeepSpaces bool
+	// Backoff is used to score n-grams that aren't in LogProbs, as the sum
+	// of the log probability of each character. It must have an N of 1.
+	// This makes tables that only contain the most common n-grams usable,
+	// because otherwise most n-grams in short texts would score Floor.
+	Backoff *NgramScorer
+}
+
+// NewNgramScorer creates an NgramScorer from the number of times that each
+// n-gram appears in total n-grams. Keys are converted to lowercase.
+func NewNgramScorer(n int, counts map[string]float64, total float64) *NgramScorer {
+	logProbs := make(map[string]float64, len(counts))
+	for ngram, count := range counts {
+		logProbs[string(foldNgram([]byte(ngram)))] = math.Log10(count / total)
+	}
+
+	return &NgramScorer{
+		N:        n,
+		LogProbs: logProbs,
+		Floor:    math.Log10(0.01 / total),
+	}
+}
+
+// foldNgram converts letters to lowercase.
+func foldNgram(ngram []byte) []byte {
+	out := make([]byte, len(ngram))
+	for i, char := range ngram {
+		if char >= 'A' && char <= 'Z' {
+			char += 'a' - 'A'
+		}
+		out[i] = char
+	}
+
+	return out
+}
+
+// Score returns the average log probability of each n-gram in text, so
+// that texts of different lengths can be compared.
+func (n *NgramScorer) Score(text []byte) float64 {
+	// normalise whitespace, either to a single space or nothing
+	folded := make([]byte, 0, len(text))
+	for _, char := range foldNgram(text) {
+		if isWhitespace(char) {
+			if !n.KeepSpaces || (len(folded) > 0 && folded[len(folded)-1] == ' ') {
+				continue
+			}
+			char = ' '
+		}
+		folded = append(folded, char)
+	}
+
+	if len(folded) < n.N {
+		return n.Floor
+	}
+
+	var total float64
+	windows := len(folded) - n.N + 1
+	for i := 0; i < windows; i++ {
+		total += n.logProb(folded[i : i+n.N])
+	}
+
+	return total / float64(windows)
+}
+
+// logProb returns the log probability of a single folded n-gram.
+func (n *NgramScorer) logProb(ngram []byte) float64 {
+	if logProb, ok := n.LogProbs[string(ngram)]; ok {
+		return logProb
+	}
+
+	if n.Backoff == nil {
+		return n.Floor
+	}
+
+	var total float64
+	for i := range ngram {
+		total += n.Backoff.logProb(ngram[i : i+1])
+	}
+
+	return total
+}
+
+// EnglishUnigramScorer scores text using EnglishFrequencies, ignoring the
+// order of letters. It's used as the Backoff for the other English
+// n-gram scorers.
+var EnglishUnigramScorer = func() *NgramScorer {
+	// scale to counts from a million letters, so that the floor is much
+	// lower than the rarest letter
+	const total = 1e6
+
+	counts := make(map[string]float64, len(EnglishFrequencies))
+	for i, freq := range EnglishFrequencies {
+		counts[string(rune('a'+i))] = freq * total
+	}
+
+	return NewNgramScorer(1, counts, total)
+}()
+
+// EnglishBigramScorer scores text using only the 42 most common English
+// bigrams, as percentages: http://norvig.com/mayzner.html
+//
+// Every other bigram backs off to EnglishUnigramScorer, so for most text
+// this is closer to a unigram score with a bonus for common bigrams than a
+// full bigram model. Use LanguageModel.NgramScorer with a model trained on
+// a large corpus for that.
+var EnglishBigramScorer = withBackoff(2, map[string]float64{
+	"th": 3.56, "he": 3.07, "in": 2.43, "er": 2.05, "an": 1.99, "re": 1.85,
+	"on": 1.76, "at": 1.49, "en": 1.45, "nd": 1.35, "ti": 1.34, "es": 1.34,
+	"or": 1.28, "te": 1.20, "of": 1.17, "ed": 1.17, "is": 1.13, "it": 1.12,
+	"al": 1.09, "ar": 1.07, "st": 1.05, "to": 1.04, "nt": 1.04, "ng": 0.95,
+	"se": 0.93, "ha": 0.93, "as": 0.87, "ou": 0.87, "io": 0.83, "le": 0.83,
+	"ve": 0.83, "co": 0.79, "me": 0.79, "de": 0.76, "hi": 0.76, "ri": 0.73,
+	"ro": 0.73, "ic": 0.70, "ne": 0.69, "ea": 0.69, "ra": 0.69, "ce": 0.65,
+}, 100, EnglishUnigramScorer)
+
+// EnglishQuadgramScorer scores text using only the 30 most common English
+// quadgrams, as counts from a corpus of 4,224,127,912 quadgrams:
+// http://practicalcryptography.com/cryptanalysis/text-characterisation/quadgrams/
+//
+// Every other quadgram backs off to EnglishUnigramScorer, which is nearly
+// all of them, so for most text this is a unigram score with a bonus for a
+// few very common quadgrams, not a quadgram log-likelihood. Use
+// LanguageModel.Scorer with a model trained on a large corpus for that.
+var EnglishQuadgramScorer = withBackoff(4, map[string]float64{
+	"tion": 13168375, "nthe": 11234972, "ther": 10218035, "that": 8980536,
+	"ofth": 8132597, "fthe": 8100836, "thes": 7717675, "with": 7627991,
+	"inth": 7261789, "atio": 7104943, "othe": 7042658, "tthe": 6858024,
+	"dthe": 6834618, "ingt": 6783307, "ethe": 6783037, "sand": 6770808,
+	"sthe": 6617154, "here": 6579690, "thec": 6516021, "ment": 6466946,
+	"them": 6234867, "rthe": 6227466, "thep": 6062185, "from": 5866064,
+	"this": 5689451, "ting": 5621669, "thei": 5563466, "ngth": 5462744,
+	"ions": 5406335, "andt": 5384620,
+}, 4224127912, EnglishUnigramScorer)
+
+// withBackoff is used to declare the built-in n-gram scorers, which only
+// contain the most common n-grams.
+func withBackoff(n int, counts map[string]float64, total float64, backoff *NgramScorer) *NgramScorer {
+	scorer := NewNgramScorer(n, counts, total)
+	scorer.Backoff = backoff
+
+	return scorer
+}
+
+// isPrintable reports whether a character is printable ASCII or common
+// whitespace.
+func isPrintable(char byte) bool {
+	return (char >= ' ' && char <= '~') || char == '\t' || char == '\n' || char == '\r'
+}
+
+// penalisedScorer subtracts a penalty from another scorer for every
+// non-printable character.
+type penalisedScorer struct {
+	scorer  Scorer
+	penalty float64
+}
+
+// PenaliseNonPrintable returns a scorer which subtracts penalty from the
+// score of scorer for every character that isn't printable ASCII or common
+// whitespace, because plaintext rarely contains them.
+func PenaliseNonPrintable(scorer Scorer, penalty float64) Scorer {
+	return &penalisedScorer{scorer: scorer, penalty: penalty}
+}
+
+func (p *penalisedScorer) Score(text []byte) float64 {
+	var nonPrintable int
+	for _, char := range text {
+		if !isPrintable(char) {
+			nonPrintable++
+		}
+	}
+
+	return p.scorer.Score(text) - p.penalty*float64(nonPrintable)
+}
+
+// scorers are the built-in scorers, by name.
+var scorers = map[string]Scorer{
+	"etaoin":       ETAOINScorer,
+	"chi2":         EnglishChiSquaredScorer,
+	"chi2-french":  FrenchChiSquaredScorer,
+	"chi2-german":  GermanChiSquaredScorer,
+	"chi2-spanish": SpanishChiSquaredScorer,
+	"unigram":      EnglishUnigramScorer,
+	"bigram":       EnglishBigramScorer,
+	"quadgram":     EnglishQuadgramScorer,
+	"utf8":         UTF8Scorer,
+	"json":         JSONScorer,
+	"html":         HTMLScorer,
+	"magic":        MagicScorer,
+}
+
+// ScorerNames returns the names of the built-in scorers, sorted.
+func ScorerNames() []string {
+	names := make([]string, 0, len(scorers))
+	for name := range scorers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// ParseScorer returns a built-in scorer from a spec, which is either the
+// name of a scorer or a list of names and weights to combine them with:
+//
+//	chi2
+//	utf8:1,json:2
+//
+// Scorers in a list without a weight have a weight of 1.
+func ParseScorer(spec string) (Scorer, error) {
+	var combined []Weighted
+	for _, part := range strings.Split(spec, ",") {
+		name, weight := part, 1.0
+		if i := strings.IndexByte(part, ':'); i >= 0 {
+			var err error
+			name = part[:i]
+			weight, err = strconv.ParseFloat(part[i+1:], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid scorer weight: %s", part[i+1:])
+			}
+		}
+
+		scorer, ok := scorers[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown scorer: %s", name)
+		}
+		combined = append(combined, Weighted{Scorer: scorer, Weight: weight})
+	}
+
+	if len(combined) == 1 && !strings.ContainsRune(spec, ':') {
+		return combined[0].Scorer, nil
+	}
+
+	return CombineScorers(combined...), nil
+}
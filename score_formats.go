@@ -0,0 +1,142 @@
+package cryptopals
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"unicode"
+	"unicode/utf8"
+)
+
+// UTF8Scorer scores text by the proportion of it, between 0 and 1, which is
+// valid UTF-8 made up of printable characters or common whitespace. It's
+// useful for plaintext which isn't English or isn't ASCII.
+var UTF8Scorer Scorer = ScorerFunc(func(text []byte) float64 {
+	if len(text) == 0 {
+		return 0
+	}
+
+	var valid int
+	for remaining := text; len(remaining) > 0; {
+		r, size := utf8.DecodeRune(remaining)
+		remaining = remaining[size:]
+
+		if r == utf8.RuneError && size == 1 {
+			continue
+		}
+		if unicode.IsPrint(r) || r == '\t' || r == '\n' || r == '\r' {
+			valid += size
+		}
+	}
+
+	return float64(valid) / float64(len(text))
+})
+
+// JSONScorer scores text by how much of it, between 0 and 1, can be read as
+// a JSON document. Complete and valid documents score 1. Truncated documents
+// still score highly, so that it can be used on parts of a ciphertext.
+var JSONScorer Scorer = ScorerFunc(func(text []byte) float64 {
+	if len(text) == 0 {
+		return 0
+	}
+	if json.Valid(text) {
+		return 1
+	}
+
+	// read tokens until the first error, and count how far we got
+	decoder := json.NewDecoder(bytes.NewReader(text))
+	var offset int64
+	for {
+		if _, err := decoder.Token(); err != nil {
+			break
+		}
+		offset = decoder.InputOffset()
+	}
+
+	// always less than a valid document
+	return 0.9 * float64(offset) / float64(len(text))
+})
+
+// htmlTag matches opening, closing and self-closing tags, comments and
+// doctypes.
+var htmlTag = regexp.MustCompile(`<[!/]?[a-zA-Z][^<>]*>|<!--[^<>]*-->`)
+
+// HTMLScorer scores text by the proportion of it that is printable plus the
+// proportion of it that is made up of HTML tags, between 0 and 2. Plaintext
+// that isn't HTML will score at most 1.
+var HTMLScorer Scorer = ScorerFunc(func(text []byte) float64 {
+	if len(text) == 0 {
+		return 0
+	}
+
+	var printable, tags int
+	for _, char := range text {
+		if isPrintable(char) {
+			printable++
+		}
+	}
+	for _, loc := range htmlTag.FindAllIndex(text, -1) {
+		tags += loc[1] - loc[0]
+	}
+
+	size := float64(len(text))
+	return float64(printable)/size + float64(tags)/size
+})
+
+// FileMagic are the headers at the start of common binary file formats:
+// https://en.wikipedia.org/wiki/List_of_file_signatures
+var FileMagic = map[string][]byte{
+	"png":  []byte("\x89PNG\r\n\x1a\n"),
+	"zip":  []byte("PK\x03\x04"),
+	"pdf":  []byte("%PDF-"),
+	"gzip": []byte("\x1f\x8b\x08"),
+}
+
+// MagicScorer scores text by the proportion of a FileMagic header, between 0
+// and 1, that it starts with. When several headers match, the best one is
+// used. Text which is shorter than a header is compared to the start of it.
+var MagicScorer Scorer = ScorerFunc(func(text []byte) float64 {
+	var best float64
+	for _, magic := range FileMagic {
+		size := len(magic)
+		if len(text) < size {
+			size = len(text)
+		}
+
+		var matched int
+		for matched < size && text[matched] == magic[matched] {
+			matched++
+		}
+
+		if score := float64(matched) / float64(len(magic)); score > best {
+			best = score
+		}
+	}
+
+	return best
+})
+
+// Weighted is a scorer and how much it contributes to a combined score.
+type Weighted struct {
+	Scorer Scorer
+	Weight float64
+}
+
+// weightedScorer is the sum of other scores multiplied by their weights.
+type weightedScorer []Weighted
+
+// CombineScorers returns a scorer which adds up the scores of others,
+// multiplied by their weights. Scorers return numbers on different scales,
+// so weights need to take that into account as well as their importance.
+func CombineScorers(scorers ...Weighted) Scorer {
+	return weightedScorer(scorers)
+}
+
+func (w weightedScorer) Score(text []byte) float64 {
+	var total float64
+	for _, weighted := range w {
+		total += weighted.Weight * weighted.Scorer.Score(text)
+	}
+
+	return total
+}
@@ -0,0 +1,97 @@
+package cryptopals_test
+
+import (
+	. "github.com/dcarley/cryptopals"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Format scorers", func() {
+	Describe("UTF8Scorer", func() {
+		It("should score printable UTF-8 as 1", func() {
+			Expect(UTF8Scorer.Score([]byte("héllo wörld\n"))).To(Equal(1.0))
+			Expect(UTF8Scorer.Score([]byte("привет мир"))).To(Equal(1.0))
+		})
+
+		It("should score invalid and control characters lower", func() {
+			Expect(UTF8Scorer.Score([]byte("ab\xff\x00"))).To(Equal(0.5))
+			Expect(UTF8Scorer.Score([]byte{})).To(Equal(0.0))
+		})
+	})
+
+	Describe("JSONScorer", func() {
+		It("should score valid documents as 1", func() {
+			Expect(JSONScorer.Score([]byte(`{"key": ["value", 1, true]}`))).To(Equal(1.0))
+		})
+
+		It("should score truncated documents higher than not JSON", func() {
+			truncated := JSONScorer.Score([]byte(`{"key": ["value", 1, tr`))
+			Expect(truncated).To(BeNumerically(">", 0.5))
+			Expect(truncated).To(BeNumerically("<", 1))
+			Expect(truncated).To(BeNumerically(">", JSONScorer.Score([]byte("this is not JSON"))))
+		})
+
+		It("should score empty text as 0", func() {
+			Expect(JSONScorer.Score([]byte{})).To(Equal(0.0))
+		})
+	})
+
+	Describe("HTMLScorer", func() {
+		It("should score HTML higher than plain text", func() {
+			html := HTMLScorer.Score([]byte(`<!DOCTYPE html><html><body><p>hello</p><br/></body></html>`))
+			text := HTMLScorer.Score([]byte(`hello, this is < not > html`))
+			binary := HTMLScorer.Score([]byte("\x00\x01<\x02>\x03"))
+
+			Expect(html).To(BeNumerically(">", 1))
+			Expect(text).To(Equal(1.0))
+			Expect(text).To(BeNumerically(">", binary))
+		})
+	})
+
+	Describe("MagicScorer", func() {
+		It("should score known headers as 1", func() {
+			for name, magic := range FileMagic {
+				Expect(MagicScorer.Score(append(magic, "rest of file"...))).To(Equal(1.0), name)
+			}
+		})
+
+		It("should score partial headers by how much matches", func() {
+			Expect(MagicScorer.Score([]byte("%PD"))).To(BeNumerically("~", 0.6))
+			Expect(MagicScorer.Score([]byte("PK\x03\x05"))).To(BeNumerically("~", 0.75))
+			Expect(MagicScorer.Score([]byte("hello"))).To(Equal(0.0))
+		})
+	})
+
+	Describe("BruteForceSingleByteXORWithScorer", func() {
+		It("should find JSON", func() {
+			plain := []byte(`{"id": 42, "name": "vanilla ice", "tags": ["ice", "ice", "baby"]}`)
+			xor, err := RepeatingKeyXOR(plain, []byte("j"))
+			Expect(err).ToNot(HaveOccurred())
+
+			score, err := BruteForceSingleByteXORWithScorer(xor, JSONScorer)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(score.Text).To(Equal(plain))
+		})
+
+		It("should find a PNG", func() {
+			plain := append(FileMagic["png"], "\x00\x00\x00\x0dIHDR"...)
+			xor, err := RepeatingKeyXOR(plain, []byte("p"))
+			Expect(err).ToNot(HaveOccurred())
+
+			score, err := BruteForceSingleByteXORWithScorer(xor, MagicScorer)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(score.Key).To(Equal([]byte("p")))
+		})
+	})
+
+	Describe("CombineScorers", func() {
+		It("should add up weighted scores", func() {
+			scorer := CombineScorers(
+				Weighted{Scorer: ScorerFunc(func([]byte) float64 { return 2 }), Weight: 3},
+				Weighted{Scorer: ScorerFunc(func([]byte) float64 { return 5 }), Weight: -1},
+			)
+			Expect(scorer.Score([]byte("text"))).To(Equal(1.0))
+		})
+	})
+})
@@ -0,0 +1,88 @@
+package cryptopals
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// The letter frequencies of other languages, as percentages of the letters
+// a to z, from: https://en.wikipedia.org/wiki/Letter_frequency
+//
+// Accented letters are counted as the letter without the accent.
+var (
+	// FrenchFrequencies are the proportions of each letter in French.
+	FrenchFrequencies = normaliseFrequencies([26]float64{
+		7.636, 0.901, 3.260, 3.669, 14.715, 1.066, 0.866, // a-g
+		0.737, 7.529, 0.613, 0.074, 5.456, 2.968, 7.095, // h-n
+		5.796, 2.521, 1.362, 6.693, 7.948, 7.244, 6.311, // o-u
+		1.838, 0.049, 0.427, 0.128, 0.326, // v-z
+	})
+	// GermanFrequencies are the proportions of each letter in German.
+	GermanFrequencies = normaliseFrequencies([26]float64{
+		6.516, 1.886, 2.732, 5.076, 16.396, 1.656, 3.009, // a-g
+		4.577, 6.550, 0.268, 1.417, 3.437, 2.534, 9.776, // h-n
+		2.594, 0.670, 0.018, 7.003, 7.270, 6.154, 4.166, // o-u
+		0.846, 1.921, 0.034, 0.039, 1.134, // v-z
+	})
+	// SpanishFrequencies are the proportions of each letter in Spanish.
+	SpanishFrequencies = normaliseFrequencies([26]float64{
+		11.525, 2.215, 4.019, 5.010, 12.181, 0.692, 1.768, // a-g
+		0.703, 6.247, 0.493, 0.011, 4.967, 3.157, 6.712, // h-n
+		8.683, 2.510, 0.877, 6.871, 7.977, 4.632, 2.927, // o-u
+		1.138, 0.017, 0.215, 1.008, 0.467, // v-z
+	})
+)
+
+var (
+	// FrenchChiSquaredScorer compares letter frequencies to French.
+	FrenchChiSquaredScorer = &ChiSquaredScorer{Frequencies: FrenchFrequencies, FoldAccents: true}
+	// GermanChiSquaredScorer compares letter frequencies to German.
+	GermanChiSquaredScorer = &ChiSquaredScorer{Frequencies: GermanFrequencies, FoldAccents: true}
+	// SpanishChiSquaredScorer compares letter frequencies to Spanish.
+	SpanishChiSquaredScorer = &ChiSquaredScorer{Frequencies: SpanishFrequencies, FoldAccents: true}
+)
+
+// normaliseFrequencies scales frequencies so that they add up to 1.
+func normaliseFrequencies(freqs [26]float64) [26]float64 {
+	var total float64
+	for _, freq := range freqs {
+		total += freq
+	}
+
+	for i := range freqs {
+		freqs[i] /= total
+	}
+
+	return freqs
+}
+
+// accents maps lowercase accented letters to the letter without an accent.
+var accents = map[rune]rune{
+	'à': 'a', 'á': 'a', 'â': 'a', 'ã': 'a', 'ä': 'a', 'å': 'a',
+	'ç': 'c',
+	'è': 'e', 'é': 'e', 'ê': 'e', 'ë': 'e',
+	'ì': 'i', 'í': 'i', 'î': 'i', 'ï': 'i',
+	'ñ': 'n',
+	'ò': 'o', 'ó': 'o', 'ô': 'o', 'õ': 'o', 'ö': 'o', 'œ': 'o',
+	'ù': 'u', 'ú': 'u', 'û': 'u', 'ü': 'u',
+	'ý': 'y', 'ÿ': 'y',
+	'ß': 's',
+}
+
+// foldAccents decodes UTF-8 text into runes, converting accented letters to
+// the ASCII letter without an accent. Invalid UTF-8 is kept as
+// utf8.RuneError, so that it counts as a non-letter.
+func foldAccents(text []byte) []rune {
+	runes := make([]rune, 0, len(text))
+	for len(text) > 0 {
+		r, size := utf8.DecodeRune(text)
+		text = text[size:]
+
+		if folded, ok := accents[unicode.ToLower(r)]; ok {
+			r = folded
+		}
+		runes = append(runes, r)
+	}
+
+	return runes
+}
@@ -0,0 +1,64 @@
+package cryptopals_test
+
+import (
+	. "github.com/dcarley/cryptopals"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Languages", func() {
+	var sentences = map[Scorer]string{
+		EnglishChiSquaredScorer: "The quick brown fox jumps over the lazy dog while the farmer watches from the window of his house",
+		FrenchChiSquaredScorer:  "Le renard brun saute par-dessus le chien paresseux pendant que le fermier le regarde de la fenêtre de sa maison",
+		GermanChiSquaredScorer:  "Der schnelle braune Fuchs springt über den faulen Hund, während der Bauer ihn vom Fenster seines Hauses beobachtet",
+		SpanishChiSquaredScorer: "El rápido zorro marrón salta sobre el perro perezoso mientras el granjero lo mira desde la ventana de su casa",
+	}
+
+	DescribeTable("text is closest to the frequencies of its own language",
+		func(scorer Scorer) {
+			text := []byte(sentences[scorer])
+			own := scorer.Score(text)
+			for other := range sentences {
+				if other != scorer {
+					Expect(own).To(BeNumerically(">", other.Score(text)))
+				}
+			}
+		},
+		Entry("English", EnglishChiSquaredScorer),
+		Entry("French", FrenchChiSquaredScorer),
+		Entry("German", GermanChiSquaredScorer),
+		Entry("Spanish", SpanishChiSquaredScorer),
+	)
+
+	DescribeTable("BruteForceSingleByteXORWithScorer",
+		func(scorer Scorer) {
+			plain := []byte(sentences[scorer])
+			xor, err := RepeatingKeyXOR(plain, []byte("K"))
+			Expect(err).ToNot(HaveOccurred())
+
+			score, err := BruteForceSingleByteXORWithScorer(xor, scorer)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(score.Key).To(Equal([]byte("K")))
+			Expect(score.Text).To(Equal(plain))
+		},
+		Entry("French", FrenchChiSquaredScorer),
+		Entry("German", GermanChiSquaredScorer),
+		Entry("Spanish", SpanishChiSquaredScorer),
+	)
+
+	Describe("FoldAccents", func() {
+		It("should count accented letters as the letter without an accent", func() {
+			Expect(FrenchChiSquaredScorer.Score([]byte("Café crème à la fenêtre"))).To(
+				Equal(FrenchChiSquaredScorer.Score([]byte("Cafe creme a la fenetre"))),
+			)
+		})
+
+		It("should count invalid UTF-8 as a non-letter", func() {
+			Expect(FrenchChiSquaredScorer.Score([]byte("caf\xc3"))).To(
+				Equal(FrenchChiSquaredScorer.Score([]byte("caf\x00"))),
+			)
+		})
+	})
+})
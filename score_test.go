@@ -0,0 +1,156 @@
+package cryptopals_test
+
+import (
+	"io/ioutil"
+
+	. "github.com/dcarley/cryptopals"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Score", func() {
+	englishScorers := []TableEntry{
+		Entry("ETAOIN", ETAOINScorer),
+		Entry("chi-squared", EnglishChiSquaredScorer),
+		Entry("bigrams", EnglishBigramScorer),
+		Entry("quadgrams", EnglishQuadgramScorer),
+		Entry("chi-squared penalising non-printable", PenaliseNonPrintable(EnglishChiSquaredScorer, 100)),
+	}
+
+	DescribeTable("English scores higher than not English",
+		func(scorer Scorer) {
+			english := scorer.Score([]byte("This is a real sentence, written in proper English"))
+
+			for _, text := range []string{
+				"xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx",
+				"qui1Chux(euZae9Ua3pooququi1Chux(euZae9Ua3pooquqqqq",
+				"01234567890123456789012345678901234567890123456789",
+				"\x00\x01\x02\x03\x04\x05\x06\x07\x08\x09\x0a\x0b\x0c\x0d\x0e\x0f\x10\x11",
+			} {
+				Expect(english).To(BeNumerically(">", scorer.Score([]byte(text))), text)
+			}
+		},
+		englishScorers...,
+	)
+
+	DescribeTable("BruteForceSingleByteXORWithScorer",
+		func(scorer Scorer) {
+			xor, err := HexDecode([]byte("1b37373331363f78151b7f2b783431333d78397828372d363c78373e783a393b3736"))
+			Expect(err).ToNot(HaveOccurred())
+
+			score, err := BruteForceSingleByteXORWithScorer(xor, scorer)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(score.Text).To(Equal([]byte("Cooking MC's like a pound of bacon")))
+			Expect(score.Score).To(Equal(scorer.Score(score.Text)))
+		},
+		englishScorers...,
+	)
+
+	DescribeTable("BruteForceMultiByteXORWithScorer",
+		func(scorer Scorer) {
+			b64, err := ioutil.ReadFile("fixtures/s1c6")
+			Expect(err).ToNot(HaveOccurred())
+			xor, err := Base64Decode(b64)
+			Expect(err).ToNot(HaveOccurred())
+
+			score, err := BruteForceMultiByteXORWithScorer(xor, scorer)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(score.Key).To(Equal([]byte("Terminator X: Bring the noise")))
+		},
+		englishScorers...,
+	)
+
+	Describe("ChiSquaredScorer", func() {
+		It("should not be fooled by spaces", func() {
+			english := []byte("Now that the party is jumping")
+			spaces := []byte("                             ")
+
+			Expect(ETAOINScorer.Score(spaces)).To(BeNumerically(">", ETAOINScorer.Score(english)))
+			Expect(EnglishChiSquaredScorer.Score(english)).To(BeNumerically(">", EnglishChiSquaredScorer.Score(spaces)))
+		})
+
+		It("should score empty text lowest", func() {
+			Expect(EnglishChiSquaredScorer.Score([]byte{})).To(BeNumerically("<", EnglishChiSquaredScorer.Score([]byte("\x00"))))
+		})
+	})
+
+	Describe("NgramScorer", func() {
+		It("should score known n-grams by their probability", func() {
+			scorer := NewNgramScorer(2, map[string]float64{"AB": 10, "bc": 1}, 100)
+			Expect(scorer.Score([]byte("ab"))).To(BeNumerically("~", -1))
+			Expect(scorer.Score([]byte("abc"))).To(BeNumerically("~", -1.5))
+			Expect(scorer.Score([]byte("xy"))).To(BeNumerically("~", -4))
+		})
+
+		It("should remove whitespace by default", func() {
+			scorer := NewNgramScorer(2, map[string]float64{"ab": 10}, 100)
+			Expect(scorer.Score([]byte("a\r\n b"))).To(BeNumerically("~", -1))
+		})
+
+		It("should collapse whitespace when keeping spaces", func() {
+			scorer := NewNgramScorer(2, map[string]float64{"a ": 10, " b": 10}, 100)
+			scorer.KeepSpaces = true
+			Expect(scorer.Score([]byte("a\r\n b"))).To(BeNumerically("~", -1))
+		})
+
+		It("should back off to single characters for unknown n-grams", func() {
+			scorer := NewNgramScorer(2, map[string]float64{"ab": 10}, 100)
+			scorer.Backoff = NewNgramScorer(1, map[string]float64{"a": 10, "b": 1}, 100)
+			Expect(scorer.Score([]byte("ba"))).To(BeNumerically("~", -3))
+			Expect(scorer.Score([]byte("bx"))).To(BeNumerically("~", -6))
+		})
+
+		It("should back off to unigrams for quadgrams that aren't in the built-in table", func() {
+			Expect(EnglishQuadgramScorer.Score([]byte("zebr"))).To(Equal(EnglishUnigramScorer.Score([]byte("z")) +
+				EnglishUnigramScorer.Score([]byte("e")) +
+				EnglishUnigramScorer.Score([]byte("b")) +
+				EnglishUnigramScorer.Score([]byte("r"))))
+		})
+
+		It("should score text shorter than N at the floor", func() {
+			Expect(EnglishQuadgramScorer.Score([]byte("the"))).To(Equal(EnglishQuadgramScorer.Floor))
+		})
+	})
+
+	Describe("ParseScorer", func() {
+		It("should return a scorer by name", func() {
+			scorer, err := ParseScorer("chi2")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(scorer).To(Equal(EnglishChiSquaredScorer))
+		})
+
+		It("should combine scorers with weights", func() {
+			scorer, err := ParseScorer("utf8:2,json, magic:0.5")
+			Expect(err).ToNot(HaveOccurred())
+
+			text := []byte(`{"a": 1}`)
+			Expect(scorer.Score(text)).To(Equal(2*UTF8Scorer.Score(text) + JSONScorer.Score(text) + 0.5*MagicScorer.Score(text)))
+		})
+
+		It("should be able to parse every name", func() {
+			for _, name := range ScorerNames() {
+				_, err := ParseScorer(name)
+				Expect(err).ToNot(HaveOccurred(), name)
+			}
+		})
+
+		DescribeTable("errors",
+			func(spec, message string) {
+				_, err := ParseScorer(spec)
+				Expect(err).To(MatchError(message))
+			},
+			Entry("unknown name", "chi2,klingon", "unknown scorer: klingon"),
+			Entry("empty", "", "unknown scorer: "),
+			Entry("invalid weight", "chi2:lots", "invalid scorer weight: lots"),
+		)
+	})
+
+	Describe("PenaliseNonPrintable", func() {
+		It("should subtract the penalty for each non-printable character", func() {
+			scorer := PenaliseNonPrintable(ETAOINScorer, 10)
+			Expect(scorer.Score([]byte("etaoin\x00\x01\xff\t\r\n"))).To(Equal(float64(6 - 30)))
+		})
+	})
+})
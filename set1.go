@@ -2,10 +2,17 @@ package cryptopals
 
 import (
 	"bytes"
+	"context"
 	"crypto/aes"
+	"encoding/binary"
+	"errors"
 	"fmt"
-	"regexp"
+	"io"
+	"io/ioutil"
+	"math/bits"
+	"runtime"
 	"sort"
+	"sync"
 )
 
 // decToHex is used to lookup a single hex value in decimal.
@@ -17,18 +24,21 @@ var decToHex = [16]byte{
 // Encodes a decimal byte slice to a hexadecimal byte slice
 func HexEncode(text []byte) []byte {
 	// output is always twice the size of input
-	out := make([]byte, len(text)*2)
+	return AppendHexEncode(make([]byte, 0, len(text)*2), text)
+}
 
+// AppendHexEncode hex encodes src, appends it to dst and returns the
+// extended slice. It doesn't allocate if dst has enough capacity.
+func AppendHexEncode(dst, src []byte) []byte {
 	// based on this article:
 	// https://learn.sparkfun.com/tutorials/hexadecimal#converting-tofrom-decimal
-	for i, char := range text {
-		secondDigit := decToHex[char%16]
+	for _, char := range src {
 		firstDigit := decToHex[char/16]
-		out[i*2] = firstDigit
-		out[i*2+1] = secondDigit
+		secondDigit := decToHex[char%16]
+		dst = append(dst, firstDigit, secondDigit)
 	}
 
-	return out
+	return dst
 }
 
 // hexToDec is used to lookup a single decimal value in hex.
@@ -51,32 +61,62 @@ func hexToDec(char byte) (byte, error) {
 	return val, nil
 }
 
+// ErrOddHexLength is returned when hex input doesn't contain an even number
+// of characters, so the last byte is incomplete.
+var ErrOddHexLength = errors.New("input must be an even size")
+
+// DecodeError is returned when a decoder finds a character that isn't valid
+// for the encoding. Offset is the position of the character in the input.
+type DecodeError struct {
+	Offset int64
+	Reason string
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("%s at offset %d", e.Reason, e.Offset)
+}
+
 // HexDecode decodes a hexadecimal byte slice to a decimal byte slice
 func HexDecode(text []byte) ([]byte, error) {
 	if len(text)%2 != 0 {
-		return []byte{}, fmt.Errorf("input must be an even size")
+		return []byte{}, ErrOddHexLength
 	}
 
 	// output is always half the size of input
-	out := make([]byte, len(text)/2)
+	out, err := AppendHexDecode(make([]byte, 0, len(text)/2), text)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	return out, nil
+}
+
+// AppendHexDecode decodes hexadecimal src, appends it to dst and returns the
+// extended slice. It doesn't allocate if dst has enough capacity. If an
+// invalid character is found then the bytes decoded before it are still
+// appended and a *DecodeError is returned.
+func AppendHexDecode(dst, src []byte) ([]byte, error) {
+	if len(src)%2 != 0 {
+		return dst, ErrOddHexLength
+	}
 
 	// based on this article:
 	// https://learn.sparkfun.com/tutorials/hexadecimal#converting-tofrom-decimal
-	for i := 0; i < len(out); i++ {
-		firstDigit, err := hexToDec(text[i*2])
+	for i := 0; i < len(src); i += 2 {
+		firstDigit, err := hexToDec(src[i])
 		if err != nil {
-			return []byte{}, err
+			return dst, &DecodeError{Offset: int64(i), Reason: err.Error()}
 		}
 
-		secondDigit, err := hexToDec(text[i*2+1])
+		secondDigit, err := hexToDec(src[i+1])
 		if err != nil {
-			return []byte{}, err
+			return dst, &DecodeError{Offset: int64(i + 1), Reason: err.Error()}
 		}
 
-		out[i] = firstDigit*16 + secondDigit
+		dst = append(dst, firstDigit*16+secondDigit)
 	}
 
-	return out, nil
+	return dst, nil
 }
 
 // decToBase64 is used to lookup base64 characters using zero-indexed 6bit
@@ -91,71 +131,7 @@ const sixBitsMax = 0x3F
 
 // Base64Encode encodes a byte slice into a base64 byte slice
 func Base64Encode(text []byte) []byte {
-	// 4 bytes out for every 3 bytes in
-	size := len(text) / 3 * 4
-	// allow for padding if input is not divisible by 3
-	if len(text)%3 != 0 {
-		size += 4
-	}
-	out := make([]byte, size)
-
-	for i := 0; i < len(text); i += 3 {
-		// convert three 8bit characters into one 24bit value by:
-		// - converting from byte (int8) to int32
-		// - bit shifting to the left, to pad least significant bits, so that they don't overlap
-		// - bit ORing to combine the values into one
-		// - ignore the last two characters if we've reached the end of the input
-		combined := int32(text[i]) << 16
-		if i+1 < len(text) {
-			combined |= int32(text[i+1]) << 8
-		}
-		if i+2 < len(text) {
-			combined |= int32(text[i+2]) << 0
-		}
-
-		// split the 24bit value into four 6bit values by:
-		// - bit shifting to the right, so the least significant 6bits are what we want
-		// - bit ANDing against 6bits of binary 1s to extract the least significant 6bits
-		// - looking up the appropriate base64 character for the value
-		// - pad the last two characters if we've reached the end of the input
-		outIndex := i / 3 * 4
-		out[outIndex] = decToBase64[combined>>18&sixBitsMax]
-		out[outIndex+1] = decToBase64[combined>>12&sixBitsMax]
-
-		if i+1 < len(text) {
-			out[outIndex+2] = decToBase64[combined>>6&sixBitsMax]
-		} else {
-			out[outIndex+2] = '='
-		}
-
-		if i+2 < len(text) {
-			out[outIndex+3] = decToBase64[combined>>0&sixBitsMax]
-		} else {
-			out[outIndex+3] = '='
-		}
-	}
-
-	return out
-}
-
-// base64ToDec is used to lookup a zero-indexed value for a base64 character
-func base64ToDec(char byte) (int32, error) {
-	switch {
-	case char >= 'A' && char <= 'Z':
-		return int32(char) - 'A', nil
-	case char >= 'a' && char <= 'z':
-		return int32(char) - 'a' + 26, nil
-	case char >= '0' && char <= '9':
-		return int32(char) - '0' + 52, nil
-	case char == '+':
-		return 62, nil
-	case char == '/':
-		return 63, nil
-	case char == '=':
-		return 0, nil
-	}
-
-	return 0, fmt.Errorf("invalid base64 character: %s", []byte{char})
+	return StdBase64.Encode(text)
 }
 
 // StripBytes removes all occurrences of a byte from a byte slice.
@@ -178,42 +154,11 @@ func StripBytes(s []byte, c byte) []byte {
 //	- hex: 0xFF
 const eightBitsMax = 0xFF
 
-// Base64Decode decodes a base64 byte slice into an byte slice
+// Base64Decode decodes a base64 byte slice into an byte slice. Whitespace is
+// ignored. Malformed input is returned as a *DecodeError with the offset of
+// the problem.
 func Base64Decode(text []byte) ([]byte, error) {
-	text = StripBytes(text, '\n')
-
-	// 4 bytes out for every 3 bytes in
-	out := make([]byte, len(text)/4*3)
-
-	for i := 0; i < len(text); i += 4 {
-		// convert four 6bit characters into one 24bit value by:
-		// - looking up the appropriate index for the base64 character
-		// - bit shifting to the left, to pad least significant bits, so that they don't overlap
-		// - bit ORing to combine the values into one
-		var combined int32
-		for offset, shift := range []uint{18, 12, 6, 0} {
-			v, err := base64ToDec(text[i+offset])
-			if err != nil {
-				return []byte{}, err
-			}
-
-			combined |= v << shift
-		}
-
-		// split the 24bit value into three 8bit values by:
-		// - bit shifting to the right, so the least significant 8bits are what we want
-		// - bit ANDing against 8bits of binary 1s to extract the least significant 8bits
-		// - converting to byte so that we get the appropriate ASCII code
-		outIndex := i / 4 * 3
-		out[outIndex] = byte(combined >> 16 & eightBitsMax)
-		out[outIndex+1] = byte(combined >> 8 & eightBitsMax)
-		out[outIndex+2] = byte(combined >> 0 & eightBitsMax)
-	}
-
-	// remove trailing padding
-	out = bytes.TrimRight(out, "\x00")
-
-	return out, nil
+	return StdBase64.Decode(text)
 }
 
 // HexToBase64 converts hexadecimal encoded text to base64.
@@ -232,12 +177,22 @@ func FixedKeyXOR(text, key []byte) ([]byte, error) {
 		return []byte{}, fmt.Errorf("text and key must be same size: %d != %d", len(text), len(key))
 	}
 
+	// empty text and key are the same size, so not an empty key error
+	if len(key) == 0 {
+		return []byte{}, nil
+	}
+
 	return RepeatingKeyXOR(text, key)
 }
 
 // RepeatingKeyXOR encrypts some text against a repeating key of a smaller
-// size.
+// size. Use XORStream to encrypt a stream without making a copy of the
+// text.
 func RepeatingKeyXOR(text, key []byte) ([]byte, error) {
+	if len(key) == 0 {
+		return []byte{}, ErrEmptyKey
+	}
+
 	var keyIndex int
 	xor := make([]byte, len(text))
 	for i := 0; i < len(xor); i++ {
@@ -253,25 +208,55 @@ func RepeatingKeyXOR(text, key []byte) ([]byte, error) {
 	return xor, nil
 }
 
+// etaoinShrdlu is used to lookup whether a character is one of the most
+// commonly occurring letters in the English language, or a space, in either
+// case.
+var etaoinShrdlu = func() [256]bool {
+	var lookup [256]bool
+	for _, char := range []byte("ETAOIN SHRDLUetaoinshrdlu") {
+		lookup[char] = true
+	}
+
+	return lookup
+}()
+
 // ScoreEnglish returns a score indicating the likelihood that a string
 // is comprised of English words by counting the most commonly occurring
 // letters in the English language.
 func ScoreEnglish(text []byte) int {
-	re := regexp.MustCompile("(?i)[ETAOIN SHRDLU]")
-	matches := re.FindAll(text, -1)
+	var score int
+	for _, char := range text {
+		if etaoinShrdlu[char] {
+			score++
+		}
+	}
 
-	return len(matches)
+	return score
 }
 
 // KeyScore can be used to keep track of the most likely key.
 type KeyScore struct {
-	Score     int
+	Score     float64
 	Key, Text []byte
 }
 
+// better reports whether candidate has a higher score than best, which is
+// the highest scoring key so far. Scores can be negative, so a best that
+// hasn't been set yet, without a key, is always replaced.
+func better(candidate, best KeyScore) bool {
+	return best.Key == nil || candidate.Score > best.Score
+}
+
 // BruteForceSingleByteXOR finds the single byte key that some text has been
-// XORed against.
+// XORed against, using ETAOINScorer.
 func BruteForceSingleByteXOR(text []byte) (KeyScore, error) {
+	return BruteForceSingleByteXORWithScorer(text, ETAOINScorer)
+}
+
+// BruteForceSingleByteXORWithScorer finds the single byte key that some
+// text has been XORed against, using scorer to decide which plaintext is
+// most likely.
+func BruteForceSingleByteXORWithScorer(text []byte, scorer Scorer) (KeyScore, error) {
 	var highestScore KeyScore
 
 	// try all printable ASCII characters
@@ -281,12 +266,9 @@ func BruteForceSingleByteXOR(text []byte) (KeyScore, error) {
 			return highestScore, err
 		}
 
-		if score := ScoreEnglish(out); score > highestScore.Score {
-			highestScore = KeyScore{
-				Score: score,
-				Key:   []byte{key},
-				Text:  out,
-			}
+		candidate := KeyScore{Score: scorer.Score(out), Key: []byte{key}, Text: out}
+		if better(candidate, highestScore) {
+			highestScore = candidate
 		}
 	}
 
@@ -300,19 +282,86 @@ func HammingDistance(one, two []byte) (int, error) {
 		return 0, fmt.Errorf("inputs must be same length: %d != %d", len(one), len(two))
 	}
 
-	var dist int
-	for i := 0; i < len(one); i++ {
-		// find bits that differ
-		charXOR := one[i] ^ two[i]
-		// select each bit from right to left
-		for mask := 1; mask <= 128; mask *= 2 {
-			if (charXOR & byte(mask)) > 0 {
-				dist++
+	return hammingDistance(one, two), nil
+}
+
+// hammingDistance counts the bits that differ between two byte slices of
+// the same length, by:
+//
+//	- XORing 8 bytes at a time as a single 64-bit word, which leaves a 1 for
+//	  each bit that differs
+//	- counting the 1s, which is a single instruction on most CPUs
+//	- doing the same for any bytes that are left, one at a time
+func hammingDistance(one, two []byte) int {
+	var dist, i int
+	for ; i+8 <= len(one); i += 8 {
+		dist += bits.OnesCount64(binary.LittleEndian.Uint64(one[i:]) ^ binary.LittleEndian.Uint64(two[i:]))
+	}
+	for ; i < len(one); i++ {
+		dist += bits.OnesCount8(one[i] ^ two[i])
+	}
+
+	return dist
+}
+
+// hammingBufferSize is how much of each reader HammingDistanceReader reads
+// at a time.
+const hammingBufferSize = 32 * 1024
+
+// HammingDistanceReader returns the number of differences between two
+// readers, without reading all of either into memory. It returns an error
+// if they aren't the same length, after reading both to the end.
+func HammingDistanceReader(one, two io.Reader) (int64, error) {
+	var (
+		bufOne = make([]byte, hammingBufferSize)
+		bufTwo = make([]byte, hammingBufferSize)
+		dist   int64
+		size   int64
+	)
+
+	for {
+		nOne, errOne := io.ReadFull(one, bufOne)
+		nTwo, errTwo := io.ReadFull(two, bufTwo)
+		if err := hammingReadError(errOne); err != nil {
+			return 0, err
+		}
+		if err := hammingReadError(errTwo); err != nil {
+			return 0, err
+		}
+
+		if nOne != nTwo {
+			// count the rest of both so that the error has their lengths
+			restOne, err := io.Copy(ioutil.Discard, one)
+			if err != nil {
+				return 0, err
+			}
+			restTwo, err := io.Copy(ioutil.Discard, two)
+			if err != nil {
+				return 0, err
 			}
+
+			return 0, fmt.Errorf("inputs must be same length: %d != %d",
+				size+int64(nOne)+restOne, size+int64(nTwo)+restTwo)
+		}
+
+		dist += int64(hammingDistance(bufOne[:nOne], bufTwo[:nTwo]))
+		size += int64(nOne)
+
+		// a short read means that both have ended
+		if nOne < len(bufOne) {
+			return dist, nil
 		}
 	}
+}
+
+// hammingReadError returns err unless it's from io.ReadFull reaching the
+// end of a reader.
+func hammingReadError(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return nil
+	}
 
-	return dist, nil
+	return err
 }
 
 // TransposeBlocks divides the input into blocks of size and returns a slice
@@ -333,20 +382,53 @@ type KeySize struct {
 	NoramlisedDistance float64
 }
 
-// GuessXORKeySize guesses the most likely key size for some XORed text. The
-// results are sorted in order of probability.
+// KeySizeOptions control how GuessXORKeySizeWithOptions guesses key sizes.
+type KeySizeOptions struct {
+	// MinSize and MaxSize are the smallest and largest key sizes to try.
+	MinSize, MaxSize int
+	// BlockPairs is the most pairs of consecutive blocks to compare for
+	// each key size. Zero compares all of the blocks in the text.
+	BlockPairs int
+	// Guesses is how many key sizes to return.
+	Guesses int
+}
+
+// DefaultKeySizeOptions are used by GuessXORKeySize.
+var DefaultKeySizeOptions = KeySizeOptions{
+	MinSize: 2,
+	MaxSize: 40,
+	Guesses: 4,
+}
+
+// GuessXORKeySize guesses the most likely key size for some XORed text,
+// using DefaultKeySizeOptions. The results are sorted in order of
+// probability.
 func GuessXORKeySize(text []byte) ([]int, error) {
-	const (
-		attempts = 10 // how many blocks to compare for each key size
-		guesses  = 4  // how many guesses to return
-		minSize  = 2  // minimum key size
-		maxSize  = 40 // maximum key size
-	)
+	return GuessXORKeySizeWithOptions(text, DefaultKeySizeOptions)
+}
+
+// GuessXORKeySizeWithOptions guesses the most likely key size for some
+// XORed text. The results are sorted in order of probability. Key sizes
+// that are too big to have two blocks in the text are skipped, so there may
+// be fewer results than opts.Guesses.
+func GuessXORKeySizeWithOptions(text []byte, opts KeySizeOptions) ([]int, error) {
+	if err := validateKeySizeOptions(opts); err != nil {
+		return []int{}, err
+	}
+
+	var keySizes []KeySize
+	for keySize := opts.MinSize; keySize <= opts.MaxSize; keySize++ {
+		// each block is compared to the next one
+		pairs := len(text)/keySize - 1
+		if pairs < 1 {
+			break
+		}
+		if opts.BlockPairs > 0 && pairs > opts.BlockPairs {
+			pairs = opts.BlockPairs
+		}
 
-	keySizes := make([]KeySize, maxSize+1-minSize)
-	for keySize := minSize; keySize <= maxSize; keySize++ {
 		var keyDistance int
-		for i := 0; i <= attempts; i++ {
+		for i := 0; i < pairs; i++ {
 			var (
 				lower  = keySize * i
 				middle = keySize*i + keySize
@@ -361,17 +443,26 @@ func GuessXORKeySize(text []byte) ([]int, error) {
 			keyDistance += blockDistance
 		}
 
-		keySizes[keySize-minSize] = KeySize{
+		// average over the pairs, because bigger key sizes have fewer
+		keySizes = append(keySizes, KeySize{
 			Size:               keySize,
-			NoramlisedDistance: float64(keyDistance) / float64(keySize),
-		}
+			NoramlisedDistance: float64(keyDistance) / float64(pairs) / float64(keySize),
+		})
+	}
+
+	if len(keySizes) == 0 {
+		return []int{}, fmt.Errorf("text is too short for key size %d: %d bytes", opts.MinSize, len(text))
 	}
 
-	sort.Slice(keySizes, func(i, j int) bool {
+	sort.SliceStable(keySizes, func(i, j int) bool {
 		return keySizes[i].NoramlisedDistance < keySizes[j].NoramlisedDistance
 	})
 
 	// lowest normalied hamming distance is most likely to be the correct size
+	guesses := opts.Guesses
+	if guesses > len(keySizes) {
+		guesses = len(keySizes)
+	}
 	lowest := make([]int, guesses)
 	for i := range lowest {
 		lowest[i] = keySizes[i].Size
@@ -381,25 +472,146 @@ func GuessXORKeySize(text []byte) ([]int, error) {
 }
 
 // BruteForceMultiByteXOR finds the multi byte key that some text has been
-// XORed against.
+// XORed against, using ETAOINScorer.
 func BruteForceMultiByteXOR(text []byte) (KeyScore, error) {
-	keySizes, err := GuessXORKeySize(text)
+	return BruteForceMultiByteXORWithScorer(text, ETAOINScorer)
+}
+
+// BruteForceMultiByteXORWithScorer finds the multi byte key that some text
+// has been XORed against, using scorer to decide which plaintext is most
+// likely.
+func BruteForceMultiByteXORWithScorer(text []byte, scorer Scorer) (KeyScore, error) {
+	return BruteForceMultiByteXORWithOptions(text, MultiByteXOROptions{
+		KeySizeOptions: DefaultKeySizeOptions,
+		Scorer:         scorer,
+	})
+}
+
+// MultiByteXOROptions control how BruteForceMultiByteXORWithOptions finds
+// a key.
+type MultiByteXOROptions struct {
+	// KeySizeOptions are used to guess the size of the key.
+	KeySizeOptions
+	// KeySizeEstimator guesses the size of the key. It defaults to
+	// GuessXORKeySizeWithOptions.
+	KeySizeEstimator KeySizeEstimator
+	// Scorer decides which plaintext is most likely. It defaults to
+	// ETAOINScorer. It's called from several goroutines at once, so it must
+	// be safe for concurrent use, which the built-in scorers are.
+	Scorer Scorer
+	// Workers is how many key bytes to find at the same time. It defaults
+	// to GOMAXPROCS.
+	Workers int
+}
+
+// BruteForceMultiByteXORWithOptions finds the multi byte key that some
+// text has been XORed against.
+func BruteForceMultiByteXORWithOptions(text []byte, opts MultiByteXOROptions) (KeyScore, error) {
+	return BruteForceMultiByteXORContext(context.Background(), text, opts)
+}
+
+// estimateKeySizes guesses the key sizes for some XORed text with
+// opts.KeySizeEstimator, or GuessXORKeySizeWithOptions if it isn't set. A
+// custom estimator can return anything, so sizes that the text can't be
+// split into columns by are skipped, and an error is returned if none are
+// left.
+func estimateKeySizes(text []byte, opts MultiByteXOROptions) ([]int, error) {
+	estimator := opts.KeySizeEstimator
+	if estimator == nil {
+		estimator = GuessXORKeySizeWithOptions
+	}
+
+	guesses, err := estimator(text, opts.KeySizeOptions)
+	if err != nil {
+		return []int{}, err
+	}
+
+	keySizes := make([]int, 0, len(guesses))
+	for _, keySize := range guesses {
+		if keySize >= 1 && keySize <= len(text) {
+			keySizes = append(keySizes, keySize)
+		}
+	}
+
+	if len(keySizes) == 0 {
+		return []int{}, fmt.Errorf("no key sizes between 1 and %d: %v", len(text), guesses)
+	}
+
+	return keySizes, nil
+}
+
+// keyByteJob is a column of some XORed text, for a key size, that a worker
+// finds a single byte key for.
+type keyByteJob struct {
+	key    []byte
+	index  int
+	column []byte
+}
+
+// BruteForceMultiByteXORContext finds the multi byte key that some text has
+// been XORed against, finding the key for each column of each key size in a
+// pool of workers. The result is the same no matter which order the workers
+// finish in. It returns ctx.Err() if ctx is done before it finishes.
+func BruteForceMultiByteXORContext(ctx context.Context, text []byte, opts MultiByteXOROptions) (KeyScore, error) {
+	scorer := opts.Scorer
+	if scorer == nil {
+		scorer = ETAOINScorer
+	}
+
+	workers := opts.Workers
+	switch {
+	case workers < 0:
+		return KeyScore{}, fmt.Errorf("workers must not be negative: %d", workers)
+	case workers == 0:
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	keySizes, err := estimateKeySizes(text, opts)
 	if err != nil {
 		return KeyScore{}, err
 	}
 
-	highestScore := KeyScore{}
-	for _, keySize := range keySizes {
-		key := make([]byte, keySize)
-		keyBlocks := TransposeBlocks(text, keySize)
+	// each job writes to its own byte of a key, so the keys don't depend on
+	// the order that they're written in
+	keys := make([][]byte, len(keySizes))
+	jobs := make(chan keyByteJob)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				// only returns an error for an empty key, which it won't be
+				score, _ := BruteForceSingleByteXORWithScorer(job.column, scorer)
+				job.key[job.index] = score.Key[0]
+			}
+		}()
+	}
 
-		for i := 0; i < keySize; i++ {
-			score, err := BruteForceSingleByteXOR(keyBlocks[i])
-			if err != nil {
-				return KeyScore{}, err
+	var cancelled error
+queue:
+	for i, keySize := range keySizes {
+		keys[i] = make([]byte, keySize)
+		for index, column := range TransposeBlocks(text, keySize) {
+			select {
+			case jobs <- keyByteJob{key: keys[i], index: index, column: column}:
+			case <-ctx.Done():
+				cancelled = ctx.Err()
+				break queue
 			}
+		}
+	}
+	close(jobs)
+	wg.Wait()
 
-			key[i] = score.Key[0]
+	if cancelled != nil {
+		return KeyScore{}, cancelled
+	}
+
+	var highestScore KeyScore
+	for _, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return KeyScore{}, err
 		}
 
 		out, err := RepeatingKeyXOR(text, key)
@@ -407,66 +619,99 @@ func BruteForceMultiByteXOR(text []byte) (KeyScore, error) {
 			return KeyScore{}, err
 		}
 
-		if score := ScoreEnglish(out); score > highestScore.Score {
-			highestScore = KeyScore{
-				Score: score,
-				Key:   key,
-				Text:  out,
-			}
+		candidate := KeyScore{Score: scorer.Score(out), Key: key, Text: out}
+		if better(candidate, highestScore) {
+			highestScore = candidate
 		}
 	}
 
 	return highestScore, nil
 }
 
-// PKCS7PaddingStrip strips PKCS#7 padding from a byte slice.
+// PKCS7PaddingStrip strips PKCS#7 padding from a byte slice, or returns
+// ErrInvalidPadding if the text isn't whole blocks that end in valid
+// padding.
 // https://en.wikipedia.org/wiki/Padding_(cryptography)#PKCS7
-func PKCS7PaddingStrip(text []byte, blockSize int) []byte {
-	// last byte may contain the amount of padding
-	padLength := int(text[len(text)-1 : len(text)][0])
-	if padLength > blockSize {
-		// no padding
-		return text
-	}
-
-	for i := len(text) - 1; i >= len(text)-padLength; i-- {
-		if int(text[i]) != padLength {
-			// padding incorrect, but don't indicate so because:
-			// https://en.wikipedia.org/wiki/Padding_oracle_attack
-			return text
-		}
+func PKCS7PaddingStrip(text []byte, blockSize int) ([]byte, error) {
+	return PKCS7.Unpad(text, blockSize)
+}
+
+// EncryptAESECB encrypts some text with AES in ECB mode, after adding
+// PKCS#7 padding. The text isn't modified.
+func EncryptAESECB(text, key []byte) ([]byte, error) {
+	ciph, err := aes.NewCipher(key)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	out, err := PKCS7.Pad(text, ciph.BlockSize())
+	if err != nil {
+		return []byte{}, err
 	}
+	NewECBEncrypter(ciph).CryptBlocks(out, out)
 
-	return text[:len(text)-padLength]
+	return out, nil
 }
 
 // DecryptAESECB decrypts some text that has been encrypted with AES in ECB
-// mode.
+// mode, and strips the PKCS#7 padding. The text isn't modified.
 func DecryptAESECB(text, key []byte) ([]byte, error) {
+	out, err := decryptAESECBBlocks(text, key)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	return PKCS7PaddingStrip(out, aes.BlockSize)
+}
+
+// decryptAESECBBlocks decrypts whole blocks of text with AES in ECB mode,
+// without stripping any padding, so that it can be done separately.
+func decryptAESECBBlocks(text, key []byte) ([]byte, error) {
 	ciph, err := aes.NewCipher(key)
 	if err != nil {
 		return []byte{}, err
 	}
 
-	blockSize := ciph.BlockSize()
-	for i := 0; i < len(text); i += blockSize {
-		// decrypt only does one block at a time.
-		ciph.Decrypt(text[i:i+blockSize], text[i:i+blockSize])
+	if err := checkBlocks(text, ciph.BlockSize()); err != nil {
+		return []byte{}, err
+	}
+
+	out := make([]byte, len(text))
+	NewECBDecrypter(ciph).CryptBlocks(out, text)
+
+	return out, nil
+}
+
+// checkBlocks returns an error unless text is made of whole blocks, and at
+// least one of them because padding always adds one.
+func checkBlocks(text []byte, blockSize int) error {
+	switch {
+	case len(text) == 0:
+		return errors.New("ciphertext must not be empty")
+	case len(text)%blockSize != 0:
+		return fmt.Errorf("ciphertext must be a multiple of the block size %d: %d", blockSize, len(text))
 	}
 
-	return PKCS7PaddingStrip(text, blockSize), nil
+	return nil
 }
 
-// DetectECB detects whether a byte slice has been encrypted in ECB mode by
-// seeing if it has repeating blocks of data.
+// DetectECB detects whether a byte slice has been encrypted with AES in ECB
+// mode by seeing if it has repeating blocks of data.
 func DetectECB(text []byte) bool {
-	const blockSize = 16
+	return DetectECBBlockSize(text, aes.BlockSize)
+}
+
+// DetectECBBlockSize detects whether a byte slice has been encrypted in ECB
+// mode, with a cipher of any block size, by seeing if it has repeating
+// blocks of data. A partial block at the end is ignored.
+func DetectECBBlockSize(text []byte, blockSize int) bool {
+	if blockSize < 1 {
+		return false
+	}
 
-	blockMap := make(map[[blockSize]byte]int, len(text)/blockSize)
-	for i := 0; i < len(text); i += blockSize {
-		var key [blockSize]byte
-		copy(key[:], text[i:i+blockSize])
-		blockMap[key]++
+	blockMap := make(map[string]int, len(text)/blockSize)
+	for i := 0; i+blockSize <= len(text); i += blockSize {
+		blockMap[string(text[i:i+blockSize])]++
 	}
 
 	for _, count := range blockMap {
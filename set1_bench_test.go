@@ -0,0 +1,100 @@
+package cryptopals_test
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	. "github.com/dcarley/cryptopals"
+)
+
+// hammingDistanceMasks is the original implementation of HammingDistance,
+// which compares one bit at a time, to check and benchmark against.
+func hammingDistanceMasks(one, two []byte) int {
+	var dist int
+	for i := 0; i < len(one); i++ {
+		// find bits that differ
+		charXOR := one[i] ^ two[i]
+		// select each bit from right to left
+		for mask := 1; mask <= 128; mask *= 2 {
+			if (charXOR & byte(mask)) > 0 {
+				dist++
+			}
+		}
+	}
+
+	return dist
+}
+
+// hammingBenchmarkSizes are the input sizes to benchmark, in bytes.
+var hammingBenchmarkSizes = []int{16, 1024, 64 * 1024, 1024 * 1024}
+
+// randomPair returns two slices of random bytes. The source is seeded so
+// that every run benchmarks the same input.
+func randomPair(size int) ([]byte, []byte) {
+	random := rand.New(rand.NewSource(int64(size)))
+	one, two := make([]byte, size), make([]byte, size)
+	random.Read(one)
+	random.Read(two)
+
+	return one, two
+}
+
+func BenchmarkHammingDistance(b *testing.B) {
+	for _, size := range hammingBenchmarkSizes {
+		one, two := randomPair(size)
+
+		b.Run(fmt.Sprintf("size=%d", size), func(b *testing.B) {
+			b.SetBytes(int64(size))
+			for i := 0; i < b.N; i++ {
+				if _, err := HammingDistance(one, two); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkHammingDistanceMasks(b *testing.B) {
+	for _, size := range hammingBenchmarkSizes {
+		one, two := randomPair(size)
+
+		b.Run(fmt.Sprintf("size=%d", size), func(b *testing.B) {
+			b.SetBytes(int64(size))
+			for i := 0; i < b.N; i++ {
+				hammingDistanceMasks(one, two)
+			}
+		})
+	}
+}
+
+func BenchmarkHammingDistanceReader(b *testing.B) {
+	for _, size := range hammingBenchmarkSizes {
+		one, two := randomPair(size)
+
+		b.Run(fmt.Sprintf("size=%d", size), func(b *testing.B) {
+			b.SetBytes(int64(size))
+			for i := 0; i < b.N; i++ {
+				if _, err := HammingDistanceReader(bytes.NewReader(one), bytes.NewReader(two)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkGuessXORKeySize(b *testing.B) {
+	for _, size := range []int{1024, 64 * 1024} {
+		text, _ := randomPair(size)
+
+		b.Run(fmt.Sprintf("size=%d", size), func(b *testing.B) {
+			b.SetBytes(int64(size))
+			for i := 0; i < b.N; i++ {
+				if _, err := GuessXORKeySize(text); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -3,8 +3,15 @@ package cryptopals_test
 import (
 	"bufio"
 	"bytes"
+	"context"
+	"crypto/rand"
+	"fmt"
 	"io/ioutil"
 	"os"
+	"strings"
+	"sync/atomic"
+	"testing/iotest"
+	"time"
 
 	. "github.com/dcarley/cryptopals"
 
@@ -46,7 +53,7 @@ var _ = Describe("Set1", func() {
 
 			It("should return an error for invalid alphas", func() {
 				decoded, err := HexDecode([]byte("6g"))
-				Expect(err).To(MatchError("invalid hex character: g"))
+				Expect(err).To(MatchError("invalid hex character: g at offset 1"))
 				Expect(decoded).To(Equal([]byte{}))
 			})
 
@@ -123,11 +130,58 @@ var _ = Describe("Set1", func() {
 				Expect(decoded).To(Equal(input))
 			})
 
-			It("should return an error for invalid base64 characters", func() {
-				decoded, err := Base64Decode([]byte("abc!def"))
-				Expect(err).To(MatchError("invalid base64 character: !"))
-				Expect(decoded).To(Equal([]byte{}))
+			It("should decode base64 to text ending in zero bytes", func() {
+				input := []byte("hello gopher\x00\x00\x00\x00")
+
+				encoded := make([]byte, base64.StdEncoding.EncodedLen(len(input)))
+				base64.StdEncoding.Encode(encoded, input)
+
+				decoded, err := Base64Decode(encoded)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(decoded).To(Equal(input))
+			})
+
+			It("should not modify input with newlines", func() {
+				encoded := []byte("aGVs\nbG8g\nZ29w\naGVy\n")
+				original := append([]byte{}, encoded...)
+
+				_, err := Base64Decode(encoded)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(encoded).To(Equal(original))
 			})
+
+			DescribeTable("errors",
+				func(encoded, expected string) {
+					decoded, err := Base64Decode([]byte(encoded))
+					Expect(err).To(MatchError(expected))
+					Expect(err).To(BeAssignableToTypeOf(&DecodeError{}))
+					Expect(decoded).To(Equal([]byte{}))
+				},
+				Entry("invalid base64 characters",
+					"abc!def", "invalid base64 character: ! at offset 3",
+				),
+				Entry("offsets that include newlines",
+					"aGVs\nbG8g\nZ2*w", "invalid base64 character: * at offset 12",
+				),
+				Entry("truncated input",
+					"aGVsbG8gZ29", "input is truncated at offset 11",
+				),
+				Entry("truncated input after newlines",
+					"aGVsbG8g\nZ2\n", "input is truncated at offset 12",
+				),
+				Entry("padding in the middle",
+					"aGV=bG8g", "unexpected data after padding at offset 4",
+				),
+				Entry("data between padding",
+					"aG=s", "unexpected data after padding at offset 3",
+				),
+				Entry("padding in the first half of a quantum",
+					"aGVsb===", "unexpected padding at offset 5",
+				),
+				Entry("padding only",
+					"====", "unexpected padding at offset 0",
+				),
+			)
 		})
 
 		Describe("Base64Encode", func() {
@@ -185,6 +239,12 @@ var _ = Describe("Set1", func() {
 				Expect(err).To(MatchError("text and key must be same size: 8 != 4"))
 				Expect(xor).To(Equal([]byte{}))
 			})
+
+			It("should return empty text for an empty text and key", func() {
+				xor, err := FixedKeyXOR(nil, nil)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(xor).To(Equal([]byte{}))
+			})
 		})
 	})
 
@@ -247,9 +307,185 @@ I go crazy when I hear a cymbal`),
 			Expect(err).ToNot(HaveOccurred())
 			Expect(HexEncode(output)).To(Equal([]byte("0b3637272a2b2e63622c2e69692a23693a2a3c6324202d623d63343c2a26226324272765272a282b2f20430a652e2c652a3124333a653e2b2027630c692b20283165286326302e27282f")))
 		})
+
+		It("should return an error for an empty key", func() {
+			output, err := RepeatingKeyXOR([]byte("text"), []byte{})
+			Expect(err).To(Equal(ErrEmptyKey))
+			Expect(output).To(Equal([]byte{}))
+		})
 	})
 
 	Describe("Challenge6", func() {
+		Describe("GuessXORKeySizeWithOptions", func() {
+			var xor []byte
+
+			BeforeEach(func() {
+				b64, err := ioutil.ReadFile("fixtures/s1c6")
+				Expect(err).ToNot(HaveOccurred())
+				xor, err = Base64Decode(b64)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("should guess the key size for challenge 6 by default", func() {
+				sizes, err := GuessXORKeySize(xor)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(sizes).To(HaveLen(4))
+				Expect(sizes[0]).To(Equal(29))
+			})
+
+			It("should only try sizes in range", func() {
+				sizes, err := GuessXORKeySizeWithOptions(xor, KeySizeOptions{
+					MinSize:    10,
+					MaxSize:    12,
+					BlockPairs: 5,
+					Guesses:    10,
+				})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(sizes).To(ConsistOf(10, 11, 12))
+			})
+
+			It("should skip sizes which don't fit in short text", func() {
+				text, err := RepeatingKeyXOR([]byte("short text that is short"), []byte("ICE"))
+				Expect(err).ToNot(HaveOccurred())
+
+				sizes, err := GuessXORKeySizeWithOptions(text, DefaultKeySizeOptions)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(sizes).To(HaveLen(4))
+				for _, size := range sizes {
+					Expect(size).To(BeNumerically("<=", len(text)/2))
+				}
+			})
+
+			DescribeTable("errors",
+				func(text []byte, opts KeySizeOptions, message string) {
+					sizes, err := GuessXORKeySizeWithOptions(text, opts)
+					Expect(err).To(MatchError(message))
+					Expect(sizes).To(Equal([]int{}))
+				},
+				Entry("text too short", []byte("abc"), DefaultKeySizeOptions,
+					"text is too short for key size 2: 3 bytes"),
+				Entry("minimum size", []byte("abcd"), KeySizeOptions{MinSize: 0, MaxSize: 2, Guesses: 1},
+					"minimum key size must be positive: 0"),
+				Entry("maximum size", []byte("abcd"), KeySizeOptions{MinSize: 3, MaxSize: 2, Guesses: 1},
+					"maximum key size must not be less than minimum: 2 < 3"),
+				Entry("block pairs", []byte("abcd"), KeySizeOptions{MinSize: 1, MaxSize: 2, BlockPairs: -1, Guesses: 1},
+					"block pairs must not be negative: -1"),
+				Entry("guesses", []byte("abcd"), KeySizeOptions{MinSize: 1, MaxSize: 2},
+					"guesses must be positive: 0"),
+			)
+		})
+
+		Describe("BruteForceMultiByteXORWithOptions", func() {
+			It("should solve challenge 6 with a narrow range of key sizes", func() {
+				b64, err := ioutil.ReadFile("fixtures/s1c6")
+				Expect(err).ToNot(HaveOccurred())
+				xor, err := Base64Decode(b64)
+				Expect(err).ToNot(HaveOccurred())
+
+				score, err := BruteForceMultiByteXORWithOptions(xor, MultiByteXOROptions{
+					KeySizeOptions: KeySizeOptions{MinSize: 25, MaxSize: 30, BlockPairs: 20, Guesses: 1},
+					Scorer:         EnglishChiSquaredScorer,
+				})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(score.Key).To(Equal([]byte("Terminator X: Bring the noise")))
+			})
+
+			It("should return the same key with any number of workers", func() {
+				b64, err := ioutil.ReadFile("fixtures/s1c6")
+				Expect(err).ToNot(HaveOccurred())
+				xor, err := Base64Decode(b64)
+				Expect(err).ToNot(HaveOccurred())
+
+				expected, err := BruteForceMultiByteXORWithOptions(xor, MultiByteXOROptions{
+					KeySizeOptions: DefaultKeySizeOptions,
+					Workers:        1,
+				})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(expected.Key).To(Equal([]byte("Terminator X: Bring the noise")))
+
+				for _, workers := range []int{0, 2, 3, 16, 100} {
+					score, err := BruteForceMultiByteXORContext(context.Background(), xor, MultiByteXOROptions{
+						KeySizeOptions: DefaultKeySizeOptions,
+						Workers:        workers,
+					})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(score).To(Equal(expected), fmt.Sprintf("workers: %d", workers))
+				}
+			})
+
+			It("should stop when the context is cancelled", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+
+				var calls int32
+				_, err := BruteForceMultiByteXORContext(ctx, bytes.Repeat([]byte("cancel me"), 100), MultiByteXOROptions{
+					KeySizeOptions: DefaultKeySizeOptions,
+					Scorer: ScorerFunc(func([]byte) float64 {
+						atomic.AddInt32(&calls, 1)
+						cancel()
+						return 0
+					}),
+					Workers: 2,
+				})
+				Expect(err).To(Equal(context.Canceled))
+				// each column calls the scorer 96 times and only the
+				// columns already being worked on should finish
+				Expect(atomic.LoadInt32(&calls)).To(BeNumerically("<", 96*10))
+			})
+
+			It("should return an error when the deadline has passed", func() {
+				ctx, cancel := context.WithTimeout(context.Background(), -time.Second)
+				defer cancel()
+
+				_, err := BruteForceMultiByteXORContext(ctx, bytes.Repeat([]byte("too late"), 100), MultiByteXOROptions{
+					KeySizeOptions: DefaultKeySizeOptions,
+				})
+				Expect(err).To(Equal(context.DeadlineExceeded))
+			})
+
+			It("should return an error for negative workers", func() {
+				_, err := BruteForceMultiByteXORWithOptions([]byte("abcd"), MultiByteXOROptions{
+					KeySizeOptions: DefaultKeySizeOptions,
+					Workers:        -1,
+				})
+				Expect(err).To(MatchError("workers must not be negative: -1"))
+			})
+
+			DescribeTable("should skip key sizes from the estimator that don't fit the text",
+				func(keySizes []int) {
+					xor, err := RepeatingKeyXOR(bytes.Repeat([]byte("hello gopher "), 10), []byte("ICE"))
+					Expect(err).ToNot(HaveOccurred())
+
+					score, err := BruteForceMultiByteXORWithOptions(xor, MultiByteXOROptions{
+						KeySizeOptions: DefaultKeySizeOptions,
+						KeySizeEstimator: func([]byte, KeySizeOptions) ([]int, error) {
+							return keySizes, nil
+						},
+					})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(score.Key).To(Equal([]byte("ICE")))
+				},
+				Entry("zero", []int{0, 3}),
+				Entry("negative", []int{-1, 3}),
+				Entry("longer than the text", []int{3, 131}),
+			)
+
+			It("should return an error when no key sizes from the estimator fit the text", func() {
+				_, err := BruteForceMultiByteXORWithOptions([]byte("abcd"), MultiByteXOROptions{
+					KeySizeOptions: DefaultKeySizeOptions,
+					KeySizeEstimator: func([]byte, KeySizeOptions) ([]int, error) {
+						return []int{0, -1, 5}, nil
+					},
+				})
+				Expect(err).To(MatchError("no key sizes between 1 and 4: [0 -1 5]"))
+			})
+
+			It("should return an error instead of panicking on short text", func() {
+				_, err := BruteForceMultiByteXOR([]byte("abc"))
+				Expect(err).To(MatchError("text is too short for key size 2: 3 bytes"))
+			})
+		})
+
 		Describe("BruteForceMultiByteXOR", func() {
 			It("should solve example", func() {
 				inFile, err := os.Open("fixtures/s1c6")
@@ -291,6 +527,58 @@ I go crazy when I hear a cymbal`),
 				Expect(err).To(MatchError("inputs must be same length: 1 != 2"))
 				Expect(distance).To(Equal(0))
 			})
+
+			It("should count the same as comparing one bit at a time", func() {
+				for _, size := range []int{0, 1, 7, 8, 9, 63, 64, 1000} {
+					one, two := make([]byte, size), make([]byte, size)
+					_, err := rand.Read(one)
+					Expect(err).ToNot(HaveOccurred())
+					_, err = rand.Read(two)
+					Expect(err).ToNot(HaveOccurred())
+
+					distance, err := HammingDistance(one, two)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(distance).To(Equal(hammingDistanceMasks(one, two)), fmt.Sprintf("size: %d", size))
+				}
+			})
+		})
+
+		Describe("HammingDistanceReader", func() {
+			It("should solve example", func() {
+				distance, err := HammingDistanceReader(
+					iotest.HalfReader(strings.NewReader("this is a test")),
+					iotest.OneByteReader(strings.NewReader("wokka wokka!!!")),
+				)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(distance).To(Equal(int64(37)))
+			})
+
+			It("should count inputs bigger than its buffer", func() {
+				one := bytes.Repeat([]byte{0x0f}, 100000)
+				two := bytes.Repeat([]byte{0xff}, 100000)
+
+				distance, err := HammingDistanceReader(bytes.NewReader(one), iotest.HalfReader(bytes.NewReader(two)))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(distance).To(Equal(int64(400000)))
+			})
+
+			It("should return an error if lengths don't match", func() {
+				distance, err := HammingDistanceReader(
+					bytes.NewReader(make([]byte, 40000)),
+					bytes.NewReader(make([]byte, 70000)),
+				)
+				Expect(err).To(MatchError("inputs must be same length: 40000 != 70000"))
+				Expect(distance).To(Equal(int64(0)))
+			})
+
+			It("should return read errors", func() {
+				_, err := HammingDistanceReader(
+					iotest.TimeoutReader(strings.NewReader("ab")),
+					strings.NewReader("ab"),
+				)
+				Expect(err).To(Equal(iotest.ErrTimeout))
+			})
 		})
 
 		DescribeTable("TransposeBlocks",
@@ -348,40 +636,132 @@ I go crazy when I hear a cymbal`),
 				Expect(err).ToNot(HaveOccurred())
 				Expect(out).To(Equal(plain))
 			})
+
+			It("should not modify its input", func() {
+				text, err := EncryptAESECB(bytes.Repeat([]byte{0xaa}, 20), []byte("YELLOW SUBMARINE"))
+				Expect(err).ToNot(HaveOccurred())
+				encrypted := append([]byte{}, text...)
+
+				_, err = DecryptAESECB(text, []byte("YELLOW SUBMARINE"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(text).To(Equal(encrypted))
+			})
+
+			It("should return ErrInvalidPadding for invalid padding", func() {
+				out, err := DecryptAESECB(bytes.Repeat([]byte{0xaa}, 32), []byte("YELLOW SUBMARINE"))
+				Expect(err).To(Equal(ErrInvalidPadding))
+				Expect(out).To(Equal([]byte{}))
+			})
+
+			DescribeTable("errors",
+				func(text, key []byte, message string) {
+					out, err := DecryptAESECB(text, key)
+					Expect(err).To(MatchError(message))
+					Expect(out).To(Equal([]byte{}))
+				},
+				Entry("partial block", make([]byte, 20), []byte("YELLOW SUBMARINE"),
+					"ciphertext must be a multiple of the block size 16: 20"),
+				Entry("empty", []byte{}, []byte("YELLOW SUBMARINE"),
+					"ciphertext must not be empty"),
+				Entry("invalid key size", make([]byte, 16), []byte("YELLOW"),
+					"crypto/aes: invalid key size 6"),
+			)
+		})
+
+		Describe("EncryptAESECB", func() {
+			It("should encrypt the same as openssl", func() {
+				plain, err := ioutil.ReadFile("fixtures/s1c7.plain")
+				Expect(err).ToNot(HaveOccurred())
+				b64, err := ioutil.ReadFile("fixtures/s1c7")
+				Expect(err).ToNot(HaveOccurred())
+				expected, err := Base64Decode(b64)
+				Expect(err).ToNot(HaveOccurred())
+
+				out, err := EncryptAESECB(plain, []byte("YELLOW SUBMARINE"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(out).To(Equal(expected))
+			})
+
+			DescribeTable("should round trip with DecryptAESECB",
+				func(plain []byte, size int) {
+					out, err := EncryptAESECB(plain, []byte("YELLOW SUBMARINE"))
+					Expect(err).ToNot(HaveOccurred())
+					Expect(out).To(HaveLen(size))
+
+					out, err = DecryptAESECB(out, []byte("YELLOW SUBMARINE"))
+					Expect(err).ToNot(HaveOccurred())
+					Expect(out).To(Equal(plain))
+				},
+				Entry("empty", []byte{}, 16),
+				Entry("partial block", []byte("hello gopher"), 16),
+				Entry("whole block", []byte("YELLOW SUBMARINE"), 32),
+				Entry("whole block ending like padding", []byte("YELLOW SUBMARIN\x01"), 32),
+			)
+
+			It("should not modify its input", func() {
+				text := make([]byte, 12, 32)
+				copy(text, "hello gopher")
+				spare := text[:32]
+
+				_, err := EncryptAESECB(text, []byte("YELLOW SUBMARINE"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(spare).To(Equal(append([]byte("hello gopher"), make([]byte, 20)...)))
+			})
+
+			It("should return an error for an invalid key size", func() {
+				out, err := EncryptAESECB([]byte("text"), []byte("YELLOW"))
+				Expect(err).To(MatchError("crypto/aes: invalid key size 6"))
+				Expect(out).To(Equal([]byte{}))
+			})
 		})
 
 		DescribeTable("PKCS7PaddingStrip",
 			func(in, out []byte) {
 				const blockSize = 16
-				Expect(PKCS7PaddingStrip(in, blockSize)).To(Equal(out))
+				stripped, err := PKCS7PaddingStrip(in, blockSize)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(stripped).To(Equal(out))
 			},
 			Entry("strips valid padding of 4 bytes",
 				[]byte{104, 101, 108, 108, 111, 32, 103, 111, 112, 104, 101, 114, 4, 4, 4, 4},
 				[]byte{104, 101, 108, 108, 111, 32, 103, 111, 112, 104, 101, 114},
 			),
-			Entry("strips valid padding of 3 bytes",
+			Entry("strips valid padding of 1 byte",
+				[]byte{104, 101, 108, 108, 111, 32, 103, 111, 112, 104, 101, 114, 2, 2, 2, 1},
+				[]byte{104, 101, 108, 108, 111, 32, 103, 111, 112, 104, 101, 114, 2, 2, 2},
+			),
+			Entry("strips a whole block of padding",
+				[]byte{
+					104, 101, 108, 108, 111, 32, 103, 111, 112, 104, 101, 114, 32, 32, 32, 32,
+					16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16,
+				},
+				[]byte{104, 101, 108, 108, 111, 32, 103, 111, 112, 104, 101, 114, 32, 32, 32, 32},
+			),
+		)
+
+		DescribeTable("PKCS7PaddingStrip errors",
+			func(in []byte) {
+				const blockSize = 16
+				out, err := PKCS7PaddingStrip(in, blockSize)
+				Expect(err).To(Equal(ErrInvalidPadding))
+				Expect(out).To(Equal([]byte{}))
+			},
+			Entry("empty",
+				[]byte{},
+			),
+			Entry("not a whole block",
 				[]byte{104, 101, 108, 108, 111, 32, 103, 111, 112, 104, 101, 114, 3, 3, 3},
-				[]byte{104, 101, 108, 108, 111, 32, 103, 111, 112, 104, 101, 114},
 			),
-			Entry("strips valid padding as far as possible",
-				[]byte{104, 101, 108, 108, 111, 32, 103, 111, 112, 104, 101, 114, 2, 2, 2},
-				[]byte{104, 101, 108, 108, 111, 32, 103, 111, 112, 104, 101, 114, 2},
+			Entry("no padding",
+				[]byte{104, 101, 108, 108, 111, 32, 103, 111, 112, 104, 101, 114, 32, 32, 32, 32},
 			),
-			Entry("doesn't strip when there is no padding",
-				[]byte{104, 101, 108, 108, 111, 32, 103, 111, 112, 104, 101, 114},
-				[]byte{104, 101, 108, 108, 111, 32, 103, 111, 112, 104, 101, 114},
+			Entry("padding of 0 bytes",
+				[]byte{104, 101, 108, 108, 111, 32, 103, 111, 112, 104, 101, 114, 0, 0, 0, 0},
 			),
-			Entry("doesn't strip invalid padding when value doesn't match count",
-				[]byte{104, 101, 108, 108, 111, 32, 103, 111, 112, 104, 101, 114, 3, 4, 4, 4},
+			Entry("value doesn't match count",
 				[]byte{104, 101, 108, 108, 111, 32, 103, 111, 112, 104, 101, 114, 3, 4, 4, 4},
 			),
-			Entry("doesn't strip invalid padding when value is greater than block size",
-				[]byte{
-					104, 101, 108, 108, 111, 32, 103, 111,
-					112, 104, 101, 114, 20, 20, 20, 20,
-					20, 20, 20, 20, 20, 20, 20, 20,
-					20, 20, 20, 20, 20, 20, 20, 20,
-				},
+			Entry("value is greater than block size",
 				[]byte{
 					104, 101, 108, 108, 111, 32, 103, 111,
 					112, 104, 101, 114, 20, 20, 20, 20,
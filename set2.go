@@ -1,17 +1,97 @@
 package cryptopals
 
 import (
-	"bytes"
-	"math"
+	"crypto/aes"
+	"fmt"
 )
 
-// PKCS7Padding adds PKCS#7 padding to a byte slice.
+// PKCS7Padding adds PKCS#7 padding to a byte slice. A whole block of
+// padding is added if the text already fills its last block, otherwise it
+// couldn't be told apart from text ending in bytes like \x01. The text
+// isn't modified. An error is returned if blockSize isn't between 1 and
+// 255.
 // https://en.wikipedia.org/wiki/Padding_(cryptography)#PKCS7
-func PKCS7Padding(text []byte, blockSize int) []byte {
-	factor := math.Ceil(float64(len(text)) / float64(blockSize))
-	newSize := int(factor) * blockSize
-	padSize := newSize - len(text)
-	pad := bytes.Repeat([]byte{byte(padSize)}, padSize)
+func PKCS7Padding(text []byte, blockSize int) ([]byte, error) {
+	return PKCS7.Pad(text, blockSize)
+}
+
+// EncryptAESCBC encrypts some text with AES in CBC mode, after adding
+// PKCS#7 padding. The IV must be the same size as a block.
+// https://en.wikipedia.org/wiki/Block_cipher_mode_of_operation#Cipher_block_chaining_(CBC)
+func EncryptAESCBC(text, key, iv []byte) ([]byte, error) {
+	ciph, err := aes.NewCipher(key)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	blockSize := ciph.BlockSize()
+	if len(iv) != blockSize {
+		return []byte{}, fmt.Errorf("iv must be same size as block: %d != %d", len(iv), blockSize)
+	}
+
+	text, err = PKCS7.Pad(text, blockSize)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	// encrypt each block by:
+	//	- XORing it against the previous ciphertext block, or the IV for
+	//	  the first block
+	//	- encrypting the result, which becomes the previous block for the
+	//	  next one
+	out := make([]byte, 0, len(text))
+	previous := iv
+	for i := 0; i < len(text); i += blockSize {
+		block, err := FixedKeyXOR(text[i:i+blockSize], previous)
+		if err != nil {
+			return []byte{}, err
+		}
+
+		// encrypt only does one block at a time.
+		ciph.Encrypt(block, block)
+		out = append(out, block...)
+		previous = block
+	}
+
+	return out, nil
+}
+
+// DecryptAESCBC decrypts some text that has been encrypted with AES in CBC
+// mode, and strips the PKCS#7 padding. The IV must be the same size as a
+// block. The text isn't modified.
+func DecryptAESCBC(text, key, iv []byte) ([]byte, error) {
+	ciph, err := aes.NewCipher(key)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	blockSize := ciph.BlockSize()
+	if len(iv) != blockSize {
+		return []byte{}, fmt.Errorf("iv must be same size as block: %d != %d", len(iv), blockSize)
+	}
+	if err := checkBlocks(text, blockSize); err != nil {
+		return []byte{}, err
+	}
+
+	// decrypt each block by:
+	//	- decrypting it
+	//	- XORing the result against the previous ciphertext block, or the
+	//	  IV for the first block
+	out := make([]byte, 0, len(text))
+	previous := iv
+	block := make([]byte, blockSize)
+	for i := 0; i < len(text); i += blockSize {
+		// decrypt only does one block at a time.
+		ciph.Decrypt(block, text[i:i+blockSize])
+
+		plain, err := FixedKeyXOR(block, previous)
+		if err != nil {
+			return []byte{}, err
+		}
+
+		out = append(out, plain...)
+		previous = text[i : i+blockSize]
+	}
 
-	return append(text, pad...)
+	return PKCS7PaddingStrip(out, blockSize)
 }
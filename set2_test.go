@@ -1,6 +1,11 @@
 package cryptopals_test
 
 import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"io/ioutil"
+
 	. "github.com/dcarley/cryptopals"
 
 	. "github.com/onsi/ginkgo"
@@ -12,7 +17,9 @@ var _ = Describe("Set2", func() {
 	Describe("Challenge9", func() {
 		DescribeTable("PKCS7Padding",
 			func(in []byte, blockSize int, out []byte) {
-				Expect(PKCS7Padding(in, blockSize)).To(Equal(out))
+				padded, err := PKCS7Padding(in, blockSize)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(padded).To(Equal(out))
 			},
 			Entry("16 byte text and 20 byte block",
 				[]byte("YELLOW SUBMARINE"), 20,
@@ -32,12 +39,168 @@ var _ = Describe("Set2", func() {
 			),
 			Entry("16 byte text and 16 byte block",
 				[]byte("YELLOW SUBMARINE"), 16,
-				[]byte("YELLOW SUBMARINE"),
+				[]byte("YELLOW SUBMARINE\x10\x10\x10\x10\x10\x10\x10\x10\x10\x10\x10\x10\x10\x10\x10\x10"),
 			),
 			Entry("16 byte text and 8 byte block",
 				[]byte("YELLOW SUBMARINE"), 8,
-				[]byte("YELLOW SUBMARINE"),
+				[]byte("YELLOW SUBMARINE\x08\x08\x08\x08\x08\x08\x08\x08"),
 			),
+			Entry("empty text",
+				[]byte{}, 4,
+				[]byte("\x04\x04\x04\x04"),
+			),
+		)
+
+		It("should not modify its input", func() {
+			text := make([]byte, 5, 8)
+			_, err := PKCS7Padding(text, 8)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(text[:8]).To(Equal(make([]byte, 8)))
+		})
+
+		It("should return an error for a block size that the padding can't count", func() {
+			_, err := PKCS7Padding([]byte("text"), 256)
+			Expect(err).To(MatchError("block size must not be more than 255: 256"))
+		})
+	})
+
+	Describe("Challenge10", func() {
+		var (
+			key = []byte("YELLOW SUBMARINE")
+			iv  = make([]byte, 16)
+		)
+
+		Describe("DecryptAESCBC", func() {
+			It("should solve example", func() {
+				b64, err := ioutil.ReadFile("fixtures/s2c10")
+				Expect(err).ToNot(HaveOccurred())
+				text, err := Base64Decode(b64)
+				Expect(err).ToNot(HaveOccurred())
+
+				out, err := DecryptAESCBC(text, key, iv)
+				Expect(err).ToNot(HaveOccurred())
+
+				// Fixture generated from the same plaintext as challenge 7,
+				// and wrapped to the same width as the challenge, using:
+				// openssl enc -aes-128-cbc -e -nosalt -in s1c7.plain \
+				//		-K "$(echo -n 'YELLOW SUBMARINE' | xxd -p)" \
+				//		-iv 00000000000000000000000000000000 \
+				//		| openssl base64 -A | fold -w 60 > s2c10
+				plain, err := ioutil.ReadFile("fixtures/s1c7.plain")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(out).To(Equal(plain))
+				Expect(out).To(HavePrefix("I'm back and I'm ringin' the bell"))
+			})
+
+			It("should not modify its input", func() {
+				text, err := EncryptAESCBC(bytes.Repeat([]byte{0xaa}, 20), key, iv)
+				Expect(err).ToNot(HaveOccurred())
+				encrypted := append([]byte{}, text...)
+
+				_, err = DecryptAESCBC(text, key, iv)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(text).To(Equal(encrypted))
+			})
+
+			It("should return ErrInvalidPadding for invalid padding", func() {
+				out, err := DecryptAESCBC(bytes.Repeat([]byte{0xaa}, 32), key, iv)
+				Expect(err).To(Equal(ErrInvalidPadding))
+				Expect(out).To(Equal([]byte{}))
+			})
+
+			DescribeTable("errors",
+				func(text, key, iv []byte, message string) {
+					out, err := DecryptAESCBC(text, key, iv)
+					Expect(err).To(MatchError(message))
+					Expect(out).To(Equal([]byte{}))
+				},
+				Entry("partial block", make([]byte, 20), key, iv,
+					"ciphertext must be a multiple of the block size 16: 20"),
+				Entry("empty", []byte{}, key, iv,
+					"ciphertext must not be empty"),
+				Entry("invalid IV size", make([]byte, 16), key, make([]byte, 8),
+					"iv must be same size as block: 8 != 16"),
+				Entry("invalid key size", make([]byte, 16), []byte("YELLOW"), iv,
+					"crypto/aes: invalid key size 6"),
+			)
+		})
+
+		Describe("EncryptAESCBC", func() {
+			It("should encrypt the same as the fixture", func() {
+				plain, err := ioutil.ReadFile("fixtures/s1c7.plain")
+				Expect(err).ToNot(HaveOccurred())
+				b64, err := ioutil.ReadFile("fixtures/s2c10")
+				Expect(err).ToNot(HaveOccurred())
+				expected, err := Base64Decode(b64)
+				Expect(err).ToNot(HaveOccurred())
+
+				out, err := EncryptAESCBC(plain, key, iv)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(out).To(Equal(expected))
+			})
+
+			It("should encrypt the same as crypto/cipher", func() {
+				plain := []byte("hello gopher, this is more than one block")
+				iv := []byte("0123456789abcdef")
+
+				out, err := EncryptAESCBC(plain, key, iv)
+				Expect(err).ToNot(HaveOccurred())
+
+				block, err := aes.NewCipher(key)
+				Expect(err).ToNot(HaveOccurred())
+				expected, err := PKCS7Padding(plain, 16)
+				Expect(err).ToNot(HaveOccurred())
+				cipher.NewCBCEncrypter(block, iv).CryptBlocks(expected, expected)
+				Expect(out).To(Equal(expected))
+			})
+
+			DescribeTable("should round trip with DecryptAESCBC",
+				func(plain []byte, size int) {
+					out, err := EncryptAESCBC(plain, key, iv)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(out).To(HaveLen(size))
+
+					out, err = DecryptAESCBC(out, key, iv)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(out).To(Equal(plain))
+				},
+				Entry("empty", []byte{}, 16),
+				Entry("partial block", []byte("hello gopher"), 16),
+				Entry("whole blocks ending like padding", []byte("YELLOW SUBMARINEYELLOW SUBMARI\x02\x02"), 48),
+			)
+
+			It("should chain blocks so that repeated plaintext isn't repeated", func() {
+				out, err := EncryptAESCBC(bytes.Repeat([]byte("YELLOW SUBMARINE"), 4), key, iv)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(DetectECB(out)).To(BeFalse())
+			})
+
+			It("should return an error for an invalid IV size", func() {
+				out, err := EncryptAESCBC([]byte("text"), key, make([]byte, 17))
+				Expect(err).To(MatchError("iv must be same size as block: 17 != 16"))
+				Expect(out).To(Equal([]byte{}))
+			})
+		})
+	})
+
+	Describe("Challenge15", func() {
+		DescribeTable("PKCS7PaddingStrip",
+			func(in []byte, out []byte, valid bool) {
+				stripped, err := PKCS7PaddingStrip(in, 16)
+				if !valid {
+					Expect(err).To(Equal(ErrInvalidPadding))
+					return
+				}
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(stripped).To(Equal(out))
+			},
+			Entry("valid padding",
+				[]byte("ICE ICE BABY\x04\x04\x04\x04"), []byte("ICE ICE BABY"), true),
+			Entry("padding with the wrong value",
+				[]byte("ICE ICE BABY\x05\x05\x05\x05"), nil, false),
+			Entry("padding with different values",
+				[]byte("ICE ICE BABY\x01\x02\x03\x04"), nil, false),
 		)
 	})
 })
@@ -0,0 +1,32 @@
+package cryptopals
+
+import (
+	"crypto/aes"
+)
+
+// EncryptAESCTR encrypts some text with AES in CTR mode, using a nonce and
+// counter in the given layout. There's no padding, so the output is the
+// same size as the text.
+// https://en.wikipedia.org/wiki/Block_cipher_mode_of_operation#Counter_(CTR)
+func EncryptAESCTR(text, key, nonce []byte, layout CTRLayout) ([]byte, error) {
+	ciph, err := aes.NewCipher(key)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	stream, err := NewCTRStream(ciph, nonce, layout)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	out := make([]byte, len(text))
+	stream.XORKeyStream(out, text)
+
+	return out, nil
+}
+
+// DecryptAESCTR decrypts some text that has been encrypted with AES in CTR
+// mode, which is the same as encrypting it again.
+func DecryptAESCTR(text, key, nonce []byte, layout CTRLayout) ([]byte, error) {
+	return EncryptAESCTR(text, key, nonce, layout)
+}
@@ -0,0 +1,56 @@
+package cryptopals_test
+
+import (
+	"io/ioutil"
+
+	. "github.com/dcarley/cryptopals"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Set3", func() {
+	Describe("Challenge18", func() {
+		var (
+			key   = []byte("YELLOW SUBMARINE")
+			nonce = make([]byte, 8)
+		)
+
+		Describe("DecryptAESCTR", func() {
+			It("should solve example", func() {
+				b64, err := ioutil.ReadFile("fixtures/s3c18")
+				Expect(err).ToNot(HaveOccurred())
+				text, err := Base64Decode(b64)
+				Expect(err).ToNot(HaveOccurred())
+
+				out, err := DecryptAESCTR(text, key, nonce, CTR64LE)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(string(out)).To(Equal("Yo, VIP Let's kick it Ice, Ice, baby Ice, Ice, baby "))
+			})
+		})
+
+		Describe("EncryptAESCTR", func() {
+			It("should round trip with DecryptAESCTR without padding", func() {
+				plain := []byte("hello gopher")
+				out, err := EncryptAESCTR(plain, key, nonce, CTR64LE)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(out).To(HaveLen(len(plain)))
+
+				out, err = DecryptAESCTR(out, key, nonce, CTR64LE)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(out).To(Equal(plain))
+			})
+
+			DescribeTable("errors",
+				func(key, nonce []byte, message string) {
+					out, err := EncryptAESCTR([]byte("text"), key, nonce, CTR64LE)
+					Expect(err).To(MatchError(message))
+					Expect(out).To(Equal([]byte{}))
+				},
+				Entry("invalid key size", []byte("YELLOW"), nonce, "crypto/aes: invalid key size 6"),
+				Entry("invalid nonce size", key, make([]byte, 12), "nonce must be 8 bytes: 12"),
+			)
+		})
+	})
+})
@@ -0,0 +1,95 @@
+package cryptopals
+
+import (
+	"crypto/cipher"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrEmptyKey is returned when XORing against a key with nothing in it.
+var ErrEmptyKey = errors.New("key must not be empty")
+
+// XORStream is a repeating key XOR cipher that implements cipher.Stream,
+// so that it can be used with cipher.StreamReader and cipher.StreamWriter.
+// It keeps track of its position in the key between calls. It's also an
+// io.Seeker, which moves to a position in the stream without XORing
+// anything.
+type XORStream struct {
+	key    []byte
+	offset int64
+}
+
+// NewXORStream returns a cipher.Stream which XORs against a repeating key,
+// starting from the beginning of the key. The key is copied.
+func NewXORStream(key []byte) (*XORStream, error) {
+	if len(key) == 0 {
+		return nil, ErrEmptyKey
+	}
+
+	return &XORStream{key: append([]byte{}, key...)}, nil
+}
+
+// XORKeyStream XORs each byte of src against the next byte of the key and
+// writes it to dst, which can be the same as src. Like the other
+// cipher.Stream implementations it panics if dst is smaller than src.
+func (x *XORStream) XORKeyStream(dst, src []byte) {
+	if len(dst) < len(src) {
+		panic("cryptopals: output smaller than input")
+	}
+
+	keyIndex := int(x.offset % int64(len(x.key)))
+	for i, char := range src {
+		dst[i] = char ^ x.key[keyIndex]
+
+		// repeat the beginning of the key if we've reached the end
+		keyIndex++
+		if keyIndex == len(x.key) {
+			keyIndex = 0
+		}
+	}
+
+	x.offset += int64(len(src))
+}
+
+// Seek sets the offset of the next byte to be XORed, relative to the start
+// of the stream or the current offset. The stream doesn't have an end, so
+// io.SeekEnd isn't supported.
+func (x *XORStream) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += x.offset
+	default:
+		return x.offset, fmt.Errorf("unsupported whence: %d", whence)
+	}
+
+	if offset < 0 {
+		return x.offset, fmt.Errorf("negative offset: %d", offset)
+	}
+	x.offset = offset
+
+	return x.offset, nil
+}
+
+// NewXORReader returns a reader which XORs everything read from r against
+// a repeating key.
+func NewXORReader(r io.Reader, key []byte) (io.Reader, error) {
+	stream, err := NewXORStream(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.StreamReader{S: stream, R: r}, nil
+}
+
+// NewXORWriter returns a writer which XORs everything against a repeating
+// key before writing it to w.
+func NewXORWriter(w io.Writer, key []byte) (io.Writer, error) {
+	stream, err := NewXORStream(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.StreamWriter{S: stream, W: w}, nil
+}
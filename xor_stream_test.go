@@ -0,0 +1,138 @@
+package cryptopals_test
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing/iotest"
+
+	. "github.com/dcarley/cryptopals"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("XORStream", func() {
+	const (
+		plain     = "Burning 'em, if you ain't quick and nimble\nI go crazy when I hear a cymbal"
+		encrypted = "0b3637272a2b2e63622c2e69692a23693a2a3c6324202d623d63343c2a26226324272765272a282b2f20430a652e2c652a3124333a653e2b2027630c692b20283165286326302e27282f"
+	)
+
+	var expected []byte
+
+	BeforeEach(func() {
+		var err error
+		expected, err = HexDecode([]byte(encrypted))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should implement cipher.Stream", func() {
+		var _ cipher.Stream = &XORStream{}
+	})
+
+	It("should keep its place in the key between calls", func() {
+		stream, err := NewXORStream([]byte("ICE"))
+		Expect(err).ToNot(HaveOccurred())
+
+		out := []byte(plain)
+		for i := 0; i < len(out); i += 5 {
+			end := i + 5
+			if end > len(out) {
+				end = len(out)
+			}
+			// in place
+			stream.XORKeyStream(out[i:end], out[i:end])
+		}
+		Expect(out).To(Equal(expected))
+	})
+
+	It("should copy the key", func() {
+		key := []byte("ICE")
+		stream, err := NewXORStream(key)
+		Expect(err).ToNot(HaveOccurred())
+		key[0] = 'X'
+
+		out := make([]byte, 3)
+		stream.XORKeyStream(out, []byte("Bur"))
+		Expect(out).To(Equal(expected[:3]))
+	})
+
+	It("should panic when the output is smaller than the input", func() {
+		stream, err := NewXORStream([]byte("ICE"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(func() { stream.XORKeyStream(make([]byte, 1), []byte("ab")) }).To(Panic())
+	})
+
+	It("should return an error for an empty key", func() {
+		_, err := NewXORStream([]byte{})
+		Expect(err).To(Equal(ErrEmptyKey))
+		_, err = NewXORReader(strings.NewReader(""), nil)
+		Expect(err).To(Equal(ErrEmptyKey))
+		_, err = NewXORWriter(ioutil.Discard, nil)
+		Expect(err).To(Equal(ErrEmptyKey))
+	})
+
+	Describe("Seek", func() {
+		It("should move to a position in the key", func() {
+			stream, err := NewXORStream([]byte("ICE"))
+			Expect(err).ToNot(HaveOccurred())
+
+			offset, err := stream.Seek(40, io.SeekStart)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(offset).To(Equal(int64(40)))
+
+			out := make([]byte, 10)
+			stream.XORKeyStream(out, []byte(plain[40:50]))
+			Expect(out).To(Equal(expected[40:50]))
+
+			offset, err = stream.Seek(-20, io.SeekCurrent)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(offset).To(Equal(int64(30)))
+
+			stream.XORKeyStream(out, []byte(plain[30:40]))
+			Expect(out).To(Equal(expected[30:40]))
+		})
+
+		It("should return an error for offsets it can't seek to", func() {
+			stream, err := NewXORStream([]byte("ICE"))
+			Expect(err).ToNot(HaveOccurred())
+			_, err = stream.Seek(5, io.SeekStart)
+			Expect(err).ToNot(HaveOccurred())
+
+			offset, err := stream.Seek(-6, io.SeekCurrent)
+			Expect(err).To(MatchError("negative offset: -1"))
+			Expect(offset).To(Equal(int64(5)))
+
+			offset, err = stream.Seek(0, io.SeekEnd)
+			Expect(err).To(MatchError("unsupported whence: 2"))
+			Expect(offset).To(Equal(int64(5)))
+		})
+	})
+
+	Describe("NewXORReader", func() {
+		It("should XOR a stream", func() {
+			reader, err := NewXORReader(iotest.OneByteReader(strings.NewReader(plain)), []byte("ICE"))
+			Expect(err).ToNot(HaveOccurred())
+
+			out, err := ioutil.ReadAll(reader)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(out).To(Equal(expected))
+		})
+	})
+
+	Describe("NewXORWriter", func() {
+		It("should XOR a stream", func() {
+			var out bytes.Buffer
+			writer, err := NewXORWriter(&out, []byte("ICE"))
+			Expect(err).ToNot(HaveOccurred())
+
+			for _, char := range []byte(plain) {
+				_, err := writer.Write([]byte{char})
+				Expect(err).ToNot(HaveOccurred())
+			}
+			Expect(out.Bytes()).To(Equal(expected))
+		})
+	})
+})